@@ -0,0 +1,36 @@
+package main
+
+import (
+	"fmt"
+
+	"github.com/TheScenery/sem-version/pkg/semver"
+)
+
+// runCache implements `sem-version cache <subcommand>`
+func runCache(args []string) error {
+	if len(args) == 0 || args[0] != "clear" {
+		return usageError("usage: sem-version cache clear [flags]")
+	}
+	return runCacheClear(args[1:])
+}
+
+// runCacheClear implements `sem-version cache clear`
+func runCacheClear(args []string) error {
+	fs := newFlagSet("cache clear", "cache clear [flags]", "Remove the commit cache stored in .git/sem-version/.")
+	opts := addGlobalFlags(fs)
+	fs.Parse(args)
+
+	ctx, cancel := opts.context()
+	defer cancel()
+
+	repoPath, err := opts.repoPath(ctx)
+	if err != nil {
+		return err
+	}
+
+	if err := semver.ClearCache(ctx, repoPath); err != nil {
+		return err
+	}
+	fmt.Println("Cache cleared")
+	return nil
+}
@@ -0,0 +1,49 @@
+package main
+
+import (
+	"fmt"
+
+	"github.com/TheScenery/sem-version/internal/changelog"
+	"github.com/TheScenery/sem-version/pkg/semver"
+)
+
+// runChangelog implements `sem-version changelog`
+func runChangelog(args []string) error {
+	fs := newFlagSet("changelog", "changelog [flags]", "Print a Markdown changelog of the commits since the latest tag.")
+	opts := addGlobalFlags(fs)
+	fs.Parse(args)
+
+	ctx, cancel := opts.context()
+	defer cancel()
+
+	res, _, err := opts.compute(ctx)
+	if err != nil {
+		return err
+	}
+
+	if len(res.Packages) == 0 {
+		fmt.Print(changelog.Render(res.Next.String(), changelogEntries(res.Commits)))
+		return nil
+	}
+
+	for i, p := range res.Packages {
+		if i > 0 {
+			fmt.Println()
+		}
+		title := p.Package.Name + " " + p.Next.String()
+		fmt.Print(changelog.Render(title, changelogEntries(p.Commits)))
+	}
+	return nil
+}
+
+func changelogEntries(commits []semver.Commit) []changelog.Entry {
+	entries := make([]changelog.Entry, 0, len(commits))
+	for _, c := range commits {
+		// Reverted commits and commits only changing filtered files are left out
+		if c.Cancelled() || c.Excluded {
+			continue
+		}
+		entries = append(entries, changelog.Entry{Hash: c.Hash, Message: c.Message})
+	}
+	return entries
+}
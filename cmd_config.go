@@ -0,0 +1,81 @@
+package main
+
+import (
+	"fmt"
+	"os"
+)
+
+// runConfig implements `sem-version config <subcommand>`
+func runConfig(args []string) error {
+	if len(args) > 0 {
+		switch args[0] {
+		case "validate":
+			return runConfigValidate(args[1:])
+		case "test":
+			return runConfigTest(args[1:])
+		}
+	}
+	return usageError("usage: sem-version config validate|test [flags]")
+}
+
+// runConfigValidate implements `sem-version config validate`
+func runConfigValidate(args []string) error {
+	fs := newFlagSet("config validate", "config validate [flags]", "Load and check the configuration, and warn about patterns that never match or overlap.")
+	opts := addGlobalFlags(fs)
+	strict := fs.Bool("strict", false, "Fail when there are warnings")
+	fs.Parse(args)
+
+	ctx, cancel := opts.context()
+	defer cancel()
+
+	cfg, err := opts.loadConfig(ctx)
+	if err != nil {
+		return err
+	}
+
+	warnings := cfg.Lint()
+	for _, w := range warnings {
+		fmt.Fprintf(os.Stderr, "warning: %s\n", w)
+	}
+	if *strict && len(warnings) > 0 {
+		return fmt.Errorf("%d warning(s)", len(warnings))
+	}
+
+	fmt.Println("Configuration is valid")
+	return nil
+}
+
+// runConfigTest implements `sem-version config test`
+func runConfigTest(args []string) error {
+	fs := newFlagSet("config test", "config test [flags]", "Check the sample commit messages of the tests section against the bump patterns.")
+	opts := addGlobalFlags(fs)
+	fs.Parse(args)
+
+	ctx, cancel := opts.context()
+	defer cancel()
+
+	cfg, err := opts.loadConfig(ctx)
+	if err != nil {
+		return err
+	}
+	if len(cfg.Tests) == 0 {
+		return fmt.Errorf("no tests in the configuration")
+	}
+
+	failed := 0
+	for _, r := range cfg.RunTests() {
+		switch {
+		case !r.Passed():
+			failed++
+			fmt.Printf("FAIL %s\n", r)
+		case opts.verbose:
+			fmt.Printf("PASS %s\n", r)
+		}
+	}
+	if failed > 0 {
+		return fmt.Errorf("%d of %d tests failed", failed, len(cfg.Tests))
+	}
+
+	fmt.Printf("All %d tests passed\n", len(cfg.Tests))
+	return nil
+}
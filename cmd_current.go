@@ -0,0 +1,42 @@
+package main
+
+import (
+	"fmt"
+)
+
+// runCurrent implements `sem-version current`
+func runCurrent(args []string) error {
+	fs := newFlagSet("current", "current [flags]",
+		"Print the version of the checkout: the tag if HEAD is tagged, otherwise a\ndevelopment version such as v1.3.0-dev.7+g1a2b3c4 (with .dirty for uncommitted changes).")
+	opts := addGlobalFlags(fs)
+	prefix := fs.String("prefix", "v", "Version prefix (default: v)")
+	noPrefix := fs.Bool("no-prefix", false, "Output version without prefix")
+	metadata := fs.String("metadata", "", "Build metadata template appended as +..., e.g. '{{.ShortSHA}}.{{.Date}}'")
+	fs.Parse(args)
+
+	ctx, cancel := opts.context()
+	defer cancel()
+
+	res, _, err := opts.compute(ctx)
+	if err != nil {
+		return err
+	}
+
+	if len(res.Packages) == 0 {
+		v, err := addMetadata(res.Describe(), res, *metadata)
+		if err != nil {
+			return err
+		}
+		outputVersion(v, *prefix, *noPrefix)
+		return nil
+	}
+
+	for _, p := range res.Packages {
+		v, err := addMetadata(res.DescribePackage(p), res, *metadata)
+		if err != nil {
+			return err
+		}
+		fmt.Printf("%s %s\n", p.Package.Name, formatVersion(v, *prefix, *noPrefix))
+	}
+	return nil
+}
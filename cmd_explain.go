@@ -0,0 +1,64 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/TheScenery/sem-version/pkg/semver"
+)
+
+// runExplain implements `sem-version explain`
+func runExplain(args []string) error {
+	fs := newFlagSet("explain", "explain [flags]", "Explain how the next version is computed, commit by commit.")
+	opts := addGlobalFlags(fs)
+	fs.Parse(args)
+
+	ctx, cancel := opts.context()
+	defer cancel()
+
+	res, _, err := opts.compute(ctx)
+	if err != nil {
+		return err
+	}
+
+	if res.Policy != nil {
+		fmt.Printf("Branch: %s (%s)\n", res.Branch, describePolicy(res.Branch, *res.Policy))
+	}
+
+	if len(res.Packages) == 0 {
+		explainRange(res.Tag, res.Current, res.Next, res.Bump, res.Commits)
+		return nil
+	}
+
+	for i, p := range res.Packages {
+		if i > 0 {
+			fmt.Println()
+		}
+		fmt.Printf("Package %s (%s)\n", p.Package.Name, p.Package.Path)
+		explainRange(p.Tag, p.Current, p.Next, p.Bump, p.Commits)
+		if len(p.PropagatedFrom) > 0 {
+			fmt.Printf("  dependency released: %s -> at least PATCH\n", strings.Join(p.PropagatedFrom, ", "))
+		}
+	}
+	return nil
+}
+
+// explainRange prints the classification of the commits since tag and the resulting bump
+func explainRange(tag string, current, next semver.Version, bump semver.BumpType, commits []semver.Commit) {
+	if tag == "" {
+		fmt.Println("Latest tag: none")
+	} else {
+		fmt.Printf("Latest tag: %s\n", tag)
+	}
+
+	fmt.Printf("Commits since tag: %d\n", semver.CountCommits(commits))
+	for _, c := range commits {
+		fmt.Printf("  %s %-10s %s%s\n", c.Hash[:7], "["+commitLabel(c)+"]", c.Subject, revertNote(c))
+	}
+
+	if tag == "" {
+		fmt.Printf("Result: %s bump, initial version %s\n", bumpLabel(bump), next)
+	} else {
+		fmt.Printf("Result: %s bump, %s -> %s\n", bumpLabel(bump), current, next)
+	}
+}
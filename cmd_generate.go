@@ -0,0 +1,65 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+
+	"github.com/TheScenery/sem-version/internal/generate"
+)
+
+// runGenerate implements `sem-version generate go`
+func runGenerate(args []string) error {
+	if len(args) == 0 || args[0] != "go" {
+		return usageError("usage: sem-version generate go [--package name] [--out file]")
+	}
+
+	fs := newFlagSet("generate go", "generate go [flags]", "Generate a Go source file declaring the next version as constants.")
+	opts := addGlobalFlags(fs)
+	pkg := fs.String("package", "", "Go package name (default: name of the output directory)")
+	out := fs.String("out", "", "Output file (default: stdout)")
+	pkgName := fs.String("package-name", "", "Configured package whose version is generated, required in monorepo mode")
+	fs.Parse(args[1:])
+
+	if *pkg == "" {
+		if *out == "" {
+			return usageError("--package is required when writing to stdout")
+		}
+		absOut, err := filepath.Abs(*out)
+		if err != nil {
+			return err
+		}
+		*pkg = filepath.Base(filepath.Dir(absOut))
+	}
+
+	ctx, cancel := opts.context()
+	defer cancel()
+
+	res, _, err := opts.compute(ctx)
+	if err != nil {
+		return err
+	}
+
+	_, next, err := selectPackage(res, *pkgName)
+	if err != nil {
+		return err
+	}
+
+	src, err := generate.GoFile(*pkg, generate.BuildInfo{
+		Version: next,
+		Commit:  res.Commit,
+		Dirty:   res.Dirty,
+	})
+	if err != nil {
+		return err
+	}
+
+	if *out == "" {
+		_, err = os.Stdout.Write(src)
+		return err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(*out), 0755); err != nil {
+		return err
+	}
+	return os.WriteFile(*out, src, 0644)
+}
@@ -0,0 +1,38 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/TheScenery/sem-version/internal/config"
+)
+
+// runInit implements `sem-version init`
+func runInit(args []string) error {
+	fs := newFlagSet("init", "init [flags]", "Generate a default .sem-version.yaml in the repository.")
+	path := fs.String("path", ".", "Path to git repository (default: current directory)")
+	fs.Parse(args)
+
+	absPath, err := filepath.Abs(*path)
+	if err != nil {
+		return fmt.Errorf("resolving path: %w", err)
+	}
+
+	if err := generateDefaultConfig(absPath); err != nil {
+		return fmt.Errorf("generating config: %w", err)
+	}
+	fmt.Println("Generated .sem-version.yaml")
+	return nil
+}
+
+func generateDefaultConfig(dir string) error {
+	configPath := filepath.Join(dir, ".sem-version.yaml")
+
+	// Check if file exists
+	if _, err := os.Stat(configPath); err == nil {
+		return fmt.Errorf("config file already exists: %s", configPath)
+	}
+
+	return os.WriteFile(configPath, []byte(config.DefaultConfigYAML()), 0644)
+}
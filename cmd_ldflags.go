@@ -0,0 +1,123 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// runLdflags implements `sem-version ldflags`
+func runLdflags(args []string) error {
+	fs := newFlagSet("ldflags", "ldflags --var pkg.name [--var ...] [flags]", "Print -X flags setting variables to the version, commit hash and build date.")
+	opts := addGlobalFlags(fs)
+	var vars stringList
+	fs.Var(&vars, "var", "Variable to set, as pkg.name or pkg.name=field (field: version, commit, date); repeatable")
+	current := fs.Bool("current", false, "Use the current (latest tag) version instead of the next one")
+	prefix := fs.String("prefix", "v", "Version prefix (default: v)")
+	noPrefix := fs.Bool("no-prefix", false, "Output version without prefix")
+	metadata := fs.String("metadata", "", "Build metadata template appended as +..., e.g. '{{.ShortSHA}}.{{.Date}}'")
+	pkgName := fs.String("package-name", "", "Configured package whose version is set, required in monorepo mode")
+	fs.Parse(args)
+
+	if len(vars) == 0 {
+		return usageError("usage: sem-version ldflags --var main.version [--var main.commit ...]")
+	}
+
+	ctx, cancel := opts.context()
+	defer cancel()
+
+	res, _, err := opts.compute(ctx)
+	if err != nil {
+		return err
+	}
+
+	cur, next, err := selectPackage(res, *pkgName)
+	if err != nil {
+		return err
+	}
+	v := next
+	if *current {
+		v = cur
+	}
+
+	v, err = addMetadata(v, res, *metadata)
+	if err != nil {
+		return err
+	}
+
+	now, err := buildTime()
+	if err != nil {
+		return err
+	}
+
+	values := map[string]string{
+		"version": formatVersion(v, *prefix, *noPrefix),
+		"commit":  res.Commit,
+		"date":    now.UTC().Format(time.RFC3339),
+	}
+
+	flags := make([]string, 0, len(vars))
+	for _, spec := range vars {
+		name, field, err := parseLdflagsVar(spec)
+		if err != nil {
+			return err
+		}
+		flags = append(flags, ldflagsX(name, values[field]))
+	}
+
+	fmt.Println(strings.Join(flags, " "))
+	return nil
+}
+
+// parseLdflagsVar splits a --var spec into the variable and the value field.
+// Without an explicit field, it is inferred from the variable name.
+func parseLdflagsVar(spec string) (name, field string, err error) {
+	name, field, explicit := strings.Cut(spec, "=")
+	if !strings.Contains(name, ".") {
+		return "", "", fmt.Errorf("invalid variable %q: want import/path.name", name)
+	}
+
+	if !explicit {
+		ident := strings.ToLower(name[strings.LastIndex(name, ".")+1:])
+		switch {
+		case strings.Contains(ident, "version"):
+			field = "version"
+		case strings.Contains(ident, "commit"), strings.Contains(ident, "sha"):
+			field = "commit"
+		case strings.Contains(ident, "date"), strings.Contains(ident, "time"):
+			field = "date"
+		default:
+			return "", "", fmt.Errorf("cannot infer value of %s, use %s=version|commit|date", name, name)
+		}
+	}
+
+	switch field {
+	case "version", "commit", "date":
+		return name, field, nil
+	default:
+		return "", "", fmt.Errorf("unknown field %q for %s (want version, commit or date)", field, name)
+	}
+}
+
+// ldflagsX formats a single -X flag, quoting values containing spaces
+func ldflagsX(name, value string) string {
+	if strings.ContainsAny(value, " \t") {
+		return fmt.Sprintf("-X '%s=%s'", name, value)
+	}
+	return fmt.Sprintf("-X %s=%s", name, value)
+}
+
+// buildTime returns the build time, honoring SOURCE_DATE_EPOCH for
+// reproducible builds
+func buildTime() (time.Time, error) {
+	if epoch := os.Getenv("SOURCE_DATE_EPOCH"); epoch != "" {
+		sec, err := strconv.ParseInt(epoch, 10, 64)
+		if err != nil {
+			return time.Time{}, fmt.Errorf("invalid SOURCE_DATE_EPOCH: %w", err)
+		}
+		return time.Unix(sec, 0), nil
+	}
+	return time.Now(), nil
+}
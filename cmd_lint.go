@@ -0,0 +1,79 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/TheScenery/sem-version/pkg/parser"
+)
+
+// runLint implements `sem-version lint`
+func runLint(args []string) error {
+	fs := newFlagSet("lint", "lint [flags]", "Check that commit messages follow Conventional Commits.\nWithout --message or --file, the commits since the latest tag are checked.")
+	opts := addGlobalFlags(fs)
+	message := fs.String("message", "", "Commit message to check")
+	file := fs.String("file", "", "File containing the commit message to check (e.g. in a commit-msg hook)")
+	fs.Parse(args)
+
+	if *file != "" {
+		data, err := os.ReadFile(*file)
+		if err != nil {
+			return err
+		}
+		*message = stripComments(string(data))
+	}
+
+	if *message != "" {
+		if err := parser.Validate(*message); err != nil {
+			return fmt.Errorf("invalid commit message: %w", err)
+		}
+		return nil
+	}
+
+	ctx, cancel := opts.context()
+	defer cancel()
+
+	res, _, err := opts.compute(ctx)
+	if err != nil {
+		return err
+	}
+
+	commits := res.Commits
+	for _, p := range res.Packages {
+		commits = append(commits, p.Commits...)
+	}
+
+	failures := 0
+	seen := make(map[string]bool, len(commits))
+	for _, c := range commits {
+		if seen[c.Hash] || strings.HasPrefix(c.Subject, "Merge ") {
+			continue
+		}
+		seen[c.Hash] = true
+
+		if err := parser.Validate(c.Message); err != nil {
+			failures++
+			fmt.Printf("%s %s\n    %v\n", c.Hash[:7], c.Subject, err)
+		}
+	}
+
+	if failures > 0 {
+		return fmt.Errorf("%d commit(s) do not follow Conventional Commits", failures)
+	}
+	if opts.verbose {
+		fmt.Fprintf(os.Stderr, "%d commit(s) checked\n", len(seen))
+	}
+	return nil
+}
+
+// stripComments removes git comment lines from a commit message file
+func stripComments(message string) string {
+	var lines []string
+	for _, line := range strings.Split(message, "\n") {
+		if !strings.HasPrefix(line, "#") {
+			lines = append(lines, line)
+		}
+	}
+	return strings.TrimSpace(strings.Join(lines, "\n"))
+}
@@ -0,0 +1,62 @@
+package main
+
+import (
+	"fmt"
+)
+
+// runNext implements `sem-version next`, also used without a command
+func runNext(args []string) error {
+	fs := newFlagSet("next", "[next] [flags]", "Print the next version computed from the commits since the latest tag.")
+	opts := addGlobalFlags(fs)
+	prefix := fs.String("prefix", "v", "Version prefix (default: v)")
+	noPrefix := fs.Bool("no-prefix", false, "Output version without prefix")
+	jsonOutput := fs.Bool("json", false, "Output package versions as JSON (monorepo mode)")
+	writeFiles := fs.Bool("write-files", false, "Write the computed version into configured bump_files")
+	dryRun := fs.Bool("dry-run", false, "Show bump_files changes as a diff without writing them")
+	metadata := fs.String("metadata", "", "Build metadata template appended as +..., e.g. '{{.ShortSHA}}.{{.Date}}' or '{{.Env.BUILD_NUMBER}}'")
+	initConfig := fs.Bool("init", false, "Generate default config file (deprecated: use 'sem-version init')")
+	fs.Parse(args)
+
+	if *initConfig {
+		return runInit([]string{"--path", opts.path})
+	}
+
+	ctx, cancel := opts.context()
+	defer cancel()
+
+	res, cfg, err := opts.compute(ctx)
+	if err != nil {
+		return err
+	}
+
+	repoPath, err := opts.repoPath(ctx)
+	if err != nil {
+		return err
+	}
+
+	res.Next, err = addMetadata(res.Next, res, *metadata)
+	if err != nil {
+		return err
+	}
+
+	// Monorepo mode: print a version per package
+	if len(res.Packages) > 0 {
+		for i := range res.Packages {
+			res.Packages[i].Next, err = addMetadata(res.Packages[i].Next, res, *metadata)
+			if err != nil {
+				return err
+			}
+		}
+		return outputPackages(repoPath, res.Packages, *prefix, *noPrefix, *jsonOutput, opts.verbose, *writeFiles, *dryRun)
+	}
+
+	outputVersion(res.Next, *prefix, *noPrefix)
+
+	// Rewrite project files with the computed version
+	if *writeFiles || *dryRun {
+		if err := updateBumpFiles(repoPath, cfg.BumpFiles, res.Next, *dryRun); err != nil {
+			return fmt.Errorf("updating files: %w", err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,39 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/TheScenery/sem-version/pkg/semver"
+)
+
+// errNotSatisfied reports a version outside the constraint
+var errNotSatisfied = errors.New("constraint not satisfied")
+
+// runSatisfies implements `sem-version satisfies`
+func runSatisfies(args []string) error {
+	fs := newFlagSet("satisfies", "satisfies <version> <constraint>", "Check that a version satisfies a constraint such as \">=1.2.0 <2.0.0\", \"~1.4\",\n\"^0.3.1\" or \"1.x || 2.x\". Exits with status 8 when it does not.")
+	fs.Parse(args)
+
+	if fs.NArg() < 2 {
+		fs.Usage()
+		return usageError("satisfies requires a version and a constraint")
+	}
+
+	v, err := semver.ParseVersion(fs.Arg(0))
+	if err != nil {
+		return err
+	}
+
+	// Accept an unquoted constraint split over several arguments
+	c, err := semver.ParseConstraint(strings.Join(fs.Args()[1:], " "))
+	if err != nil {
+		return err
+	}
+
+	if !c.Check(v) {
+		return fmt.Errorf("%w: %s does not satisfy %s", errNotSatisfied, fs.Arg(0), c)
+	}
+	return nil
+}
@@ -0,0 +1,63 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/TheScenery/sem-version/pkg/semver"
+)
+
+// runTag implements `sem-version tag`
+func runTag(args []string) error {
+	fs := newFlagSet("tag", "tag [flags]", "Create an annotated git tag for the next version (one per released package in monorepo mode).")
+	opts := addGlobalFlags(fs)
+	message := fs.String("message", "Release %s", "Tag message, %s is replaced by the tag name")
+	dryRun := fs.Bool("dry-run", false, "Print the tags without creating them")
+	fs.Parse(args)
+
+	ctx, cancel := opts.context()
+	defer cancel()
+
+	res, _, err := opts.compute(ctx)
+	if err != nil {
+		return err
+	}
+
+	repoPath, err := opts.repoPath(ctx)
+	if err != nil {
+		return err
+	}
+
+	// Tags always use the "v" prefix, which is what tag lookup matches
+	var tags []string
+	if len(res.Packages) > 0 {
+		for _, p := range res.Packages {
+			if isReleasable(p.Tag, p.Current, p.Next) {
+				tags = append(tags, p.Package.GetTagPrefix()+p.Next.String())
+			}
+		}
+	} else if isReleasable(res.Tag, res.Current, res.Next) {
+		tags = append(tags, res.Next.String())
+	}
+
+	if len(tags) == 0 {
+		return errors.New("no releasable changes since the latest tag")
+	}
+
+	for _, tag := range tags {
+		if !*dryRun {
+			if _, err := semver.CreateTag(ctx, repoPath, tag, fmt.Sprintf(*message, tag)); err != nil {
+				return err
+			}
+		}
+		fmt.Println(tag)
+	}
+	return nil
+}
+
+// isReleasable reports whether a new tag should be created: either the next
+// version differs from the latest tag, through a bump or by releasing its
+// prerelease, or there is no tag yet
+func isReleasable(tag string, current, next semver.Version) bool {
+	return tag == "" || next != current
+}
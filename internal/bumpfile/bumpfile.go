@@ -0,0 +1,343 @@
+package bumpfile
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/TheScenery/sem-version/internal/config"
+	"gopkg.in/yaml.v3"
+)
+
+// Change represents the rewrite of a single file
+type Change struct {
+	Path string
+	Old  []byte
+	New  []byte
+}
+
+// Update computes the new content of a bump file with the given version
+// The file is not written, see Change.Write
+func Update(root string, file config.BumpFile, version string) (Change, error) {
+	path := filepath.Join(root, file.Path)
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return Change{}, err
+	}
+
+	var updated []byte
+	switch file.Format {
+	case config.FormatJSON:
+		updated, err = replaceJSON(content, file.Key, version)
+	case config.FormatYAML:
+		updated, err = replaceYAML(content, file.Key, version)
+	case config.FormatTOML:
+		updated, err = replaceTOML(content, file.Key, version)
+	case config.FormatRegex:
+		updated, err = replaceRegex(content, file.Pattern, version)
+	default:
+		err = fmt.Errorf("unknown format %q", file.Format)
+	}
+	if err != nil {
+		return Change{}, fmt.Errorf("%s: %w", file.Path, err)
+	}
+
+	return Change{Path: path, Old: content, New: updated}, nil
+}
+
+// Changed reports whether the new content differs from the old one
+func (c Change) Changed() bool {
+	return !bytes.Equal(c.Old, c.New)
+}
+
+// Write writes the new content to the file
+func (c Change) Write() error {
+	info, err := os.Stat(c.Path)
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(c.Path, c.New, info.Mode().Perm())
+}
+
+// Diff returns a unified-style diff of the change
+func (c Change) Diff(name string) string {
+	if !c.Changed() {
+		return ""
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "--- a/%s\n+++ b/%s\n", name, name)
+
+	oldLines := strings.Split(string(c.Old), "\n")
+	newLines := strings.Split(string(c.New), "\n")
+	if len(oldLines) != len(newLines) {
+		fmt.Fprintf(&b, "@@ -1,%d +1,%d @@\n", len(oldLines), len(newLines))
+		for _, l := range oldLines {
+			b.WriteString("-" + l + "\n")
+		}
+		for _, l := range newLines {
+			b.WriteString("+" + l + "\n")
+		}
+		return b.String()
+	}
+
+	for i := range oldLines {
+		if oldLines[i] != newLines[i] {
+			fmt.Fprintf(&b, "@@ -%d +%d @@\n-%s\n+%s\n", i+1, i+1, oldLines[i], newLines[i])
+		}
+	}
+	return b.String()
+}
+
+// replaceJSON replaces the string value at the dotted key path, keeping the
+// rest of the document byte for byte
+func replaceJSON(content []byte, key, value string) ([]byte, error) {
+	type frame struct {
+		object    bool
+		expectKey bool
+		key       string
+		index     int
+	}
+
+	path := strings.Split(key, ".")
+	var stack []*frame
+
+	matches := func() bool {
+		if len(stack) != len(path) {
+			return false
+		}
+		for i, f := range stack {
+			k := f.key
+			if !f.object {
+				k = strconv.Itoa(f.index)
+			}
+			if k != path[i] {
+				return false
+			}
+		}
+		return true
+	}
+
+	afterValue := func() {
+		if len(stack) == 0 {
+			return
+		}
+		top := stack[len(stack)-1]
+		if top.object {
+			top.expectKey = true
+		} else {
+			top.index++
+		}
+	}
+
+	dec := json.NewDecoder(bytes.NewReader(content))
+	for {
+		tok, err := dec.Token()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		if len(stack) > 0 {
+			top := stack[len(stack)-1]
+			if top.object && top.expectKey {
+				if tok == json.Delim('}') {
+					stack = stack[:len(stack)-1]
+					afterValue()
+					continue
+				}
+				top.key, _ = tok.(string)
+				top.expectKey = false
+				continue
+			}
+		}
+
+		switch tok {
+		case json.Delim('{'):
+			stack = append(stack, &frame{object: true, expectKey: true})
+			continue
+		case json.Delim('['):
+			stack = append(stack, &frame{})
+			continue
+		case json.Delim(']'):
+			stack = stack[:len(stack)-1]
+			afterValue()
+			continue
+		}
+
+		if matches() {
+			if _, ok := tok.(string); !ok {
+				return nil, fmt.Errorf("key %s is not a string", key)
+			}
+			end := int(dec.InputOffset())
+			start := openingQuote(content, end-1)
+			if start < 0 {
+				return nil, fmt.Errorf("key %s: cannot locate value", key)
+			}
+			encoded, err := json.Marshal(value)
+			if err != nil {
+				return nil, err
+			}
+			return splice(content, start, end, encoded), nil
+		}
+		afterValue()
+	}
+
+	return nil, fmt.Errorf("key %s not found", key)
+}
+
+// openingQuote returns the index of the quote opening the JSON string that
+// is closed by the quote at index end
+func openingQuote(content []byte, end int) int {
+	for i := end - 1; i >= 0; i-- {
+		if content[i] != '"' {
+			continue
+		}
+		backslashes := 0
+		for j := i - 1; j >= 0 && content[j] == '\\'; j-- {
+			backslashes++
+		}
+		if backslashes%2 == 0 {
+			return i
+		}
+	}
+	return -1
+}
+
+// replaceYAML replaces the scalar at the dotted key path, keeping comments
+// and formatting of the document
+func replaceYAML(content []byte, key, value string) ([]byte, error) {
+	var doc yaml.Node
+	if err := yaml.Unmarshal(content, &doc); err != nil {
+		return nil, err
+	}
+	if len(doc.Content) == 0 {
+		return nil, fmt.Errorf("key %s not found", key)
+	}
+
+	node := doc.Content[0]
+	for _, part := range strings.Split(key, ".") {
+		if node.Kind != yaml.MappingNode {
+			return nil, fmt.Errorf("key %s not found", key)
+		}
+		var next *yaml.Node
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			if node.Content[i].Value == part {
+				next = node.Content[i+1]
+				break
+			}
+		}
+		if next == nil {
+			return nil, fmt.Errorf("key %s not found", key)
+		}
+		node = next
+	}
+
+	if node.Kind != yaml.ScalarNode {
+		return nil, fmt.Errorf("key %s is not a scalar", key)
+	}
+
+	var old, replacement string
+	switch node.Style {
+	case yaml.DoubleQuotedStyle:
+		old, replacement = `"`+node.Value+`"`, `"`+value+`"`
+	case yaml.SingleQuotedStyle:
+		old, replacement = `'`+node.Value+`'`, `'`+value+`'`
+	default:
+		old, replacement = node.Value, value
+	}
+
+	lineStart := lineOffset(content, node.Line)
+	start := lineStart + node.Column - 1
+	end := start + len(old)
+	if lineStart < 0 || end > len(content) || string(content[start:end]) != old {
+		return nil, fmt.Errorf("key %s: cannot locate value", key)
+	}
+
+	return splice(content, start, end, []byte(replacement)), nil
+}
+
+// tomlKeyRegex matches a `key = "value"` line in a TOML file
+var tomlKeyRegex = regexp.MustCompile(`^\s*([A-Za-z0-9_-]+)\s*=\s*(["'])([^"']*)["']`)
+
+// replaceTOML replaces the string value of key (e.g. "project.version")
+// inside its table
+func replaceTOML(content []byte, key, value string) ([]byte, error) {
+	table, name := "", key
+	if i := strings.LastIndex(key, "."); i >= 0 {
+		table, name = key[:i], key[i+1:]
+	}
+
+	current := ""
+	offset := 0
+	for _, line := range strings.SplitAfter(string(content), "\n") {
+		trimmed := strings.TrimSpace(line)
+		if strings.HasPrefix(trimmed, "[") {
+			current = strings.Trim(trimmed, "[] \t")
+		} else if current == table {
+			if m := tomlKeyRegex.FindStringSubmatchIndex(line); m != nil && line[m[2]:m[3]] == name {
+				quote := line[m[4]:m[5]]
+				replacement := quote + value + quote
+				return splice(content, offset+m[4], offset+m[7]+1, []byte(replacement)), nil
+			}
+		}
+		offset += len(line)
+	}
+
+	return nil, fmt.Errorf("key %s not found", key)
+}
+
+// replaceRegex replaces the first capture group of every pattern match
+func replaceRegex(content []byte, pattern, value string) ([]byte, error) {
+	re, err := regexp.Compile(pattern)
+	if err != nil {
+		return nil, err
+	}
+
+	matches := re.FindAllSubmatchIndex(content, -1)
+	if len(matches) == 0 {
+		return nil, fmt.Errorf("pattern %s did not match", pattern)
+	}
+
+	result := make([]byte, 0, len(content))
+	last := 0
+	for _, m := range matches {
+		if len(m) < 4 || m[2] < 0 {
+			continue
+		}
+		result = append(result, content[last:m[2]]...)
+		result = append(result, value...)
+		last = m[3]
+	}
+	result = append(result, content[last:]...)
+
+	return result, nil
+}
+
+// lineOffset returns the byte offset of the 1-based line number
+func lineOffset(content []byte, line int) int {
+	offset := 0
+	for i := 1; i < line; i++ {
+		next := bytes.IndexByte(content[offset:], '\n')
+		if next < 0 {
+			return -1
+		}
+		offset += next + 1
+	}
+	return offset
+}
+
+func splice(content []byte, start, end int, replacement []byte) []byte {
+	result := make([]byte, 0, len(content)-(end-start)+len(replacement))
+	result = append(result, content[:start]...)
+	result = append(result, replacement...)
+	return append(result, content[end:]...)
+}
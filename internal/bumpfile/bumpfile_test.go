@@ -0,0 +1,110 @@
+package bumpfile
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/TheScenery/sem-version/internal/config"
+)
+
+func TestUpdate(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		file    config.BumpFile
+		want    string
+		wantErr bool
+	}{
+		{
+			name:    "json top-level key",
+			content: "{\n  \"name\": \"app\",\n  \"version\": \"1.0.0\",\n  \"private\": true\n}\n",
+			file:    config.BumpFile{Format: config.FormatJSON, Key: "version"},
+			want:    "{\n  \"name\": \"app\",\n  \"version\": \"1.2.0\",\n  \"private\": true\n}\n",
+		},
+		{
+			name:    "json nested key skips same name elsewhere",
+			content: `{"deps": [{"version": "9.9.9"}], "meta": {"version": "1.0.0"}}`,
+			file:    config.BumpFile{Format: config.FormatJSON, Key: "meta.version"},
+			want:    `{"deps": [{"version": "9.9.9"}], "meta": {"version": "1.2.0"}}`,
+		},
+		{
+			name:    "json missing key",
+			content: `{"name": "app"}`,
+			file:    config.BumpFile{Format: config.FormatJSON, Key: "version"},
+			wantErr: true,
+		},
+		{
+			name:    "yaml plain scalar",
+			content: "apiVersion: v2\nname: chart # the chart\nversion: 1.0.0\nappVersion: \"1.0.0\"\n",
+			file:    config.BumpFile{Format: config.FormatYAML, Key: "version"},
+			want:    "apiVersion: v2\nname: chart # the chart\nversion: 1.2.0\nappVersion: \"1.0.0\"\n",
+		},
+		{
+			name:    "yaml quoted scalar",
+			content: "version: 1.0.0\nappVersion: \"1.0.0\"\n",
+			file:    config.BumpFile{Format: config.FormatYAML, Key: "appVersion"},
+			want:    "version: 1.0.0\nappVersion: \"1.2.0\"\n",
+		},
+		{
+			name:    "toml table key",
+			content: "[build-system]\nversion = \"0.1\"\n\n[project]\nname = \"app\"\nversion = '1.0.0'\n",
+			file:    config.BumpFile{Format: config.FormatTOML, Key: "project.version"},
+			want:    "[build-system]\nversion = \"0.1\"\n\n[project]\nname = \"app\"\nversion = '1.2.0'\n",
+		},
+		{
+			name:    "toml missing table",
+			content: "[package]\nversion = \"1.0.0\"\n",
+			file:    config.BumpFile{Format: config.FormatTOML, Key: "project.version"},
+			wantErr: true,
+		},
+		{
+			name:    "regex capture group",
+			content: "package buildinfo\n\nconst Version = \"1.0.0\"\n",
+			file:    config.BumpFile{Format: config.FormatRegex, Pattern: `const Version = "([^"]*)"`},
+			want:    "package buildinfo\n\nconst Version = \"1.2.0\"\n",
+		},
+		{
+			name:    "regex no match",
+			content: "package buildinfo\n",
+			file:    config.BumpFile{Format: config.FormatRegex, Pattern: `Version = "(.*)"`},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			tt.file.Path = "file"
+			if err := os.WriteFile(filepath.Join(dir, "file"), []byte(tt.content), 0644); err != nil {
+				t.Fatalf("Failed to write file: %v", err)
+			}
+
+			change, err := Update(dir, tt.file, "1.2.0")
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Update() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !tt.wantErr && string(change.New) != tt.want {
+				t.Errorf("Update() = %q, want %q", change.New, tt.want)
+			}
+		})
+	}
+}
+
+func TestChange_Diff(t *testing.T) {
+	change := Change{
+		Old: []byte("name: app\nversion: 1.0.0\n"),
+		New: []byte("name: app\nversion: 1.1.0\n"),
+	}
+
+	want := "--- a/Chart.yaml\n+++ b/Chart.yaml\n@@ -2 +2 @@\n-version: 1.0.0\n+version: 1.1.0\n"
+	if got := change.Diff("Chart.yaml"); got != want {
+		t.Errorf("Diff() = %q, want %q", got, want)
+	}
+
+	unchanged := Change{Old: []byte("a"), New: []byte("a")}
+	if got := unchanged.Diff("a"); strings.TrimSpace(got) != "" {
+		t.Errorf("Diff() of unchanged file = %q, want empty", got)
+	}
+}
@@ -0,0 +1,117 @@
+package cache
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"sync"
+)
+
+// formatVersion is bumped when the layout of cached values or the way
+// commits are classified changes, which invalidates every existing store
+const formatVersion = "2"
+
+// filePrefix starts the name of every store file
+const filePrefix = "commits-"
+
+// Store is a persistent map from commit hashes to analysis results, valid
+// for a single key such as a digest of the config. It is safe for
+// concurrent use.
+type Store[V any] struct {
+	dir string
+	key string
+
+	mu      sync.Mutex
+	entries map[string]V
+	dirty   bool
+}
+
+// file is the on-disk representation of a store
+type file[V any] struct {
+	Entries map[string]V `json:"entries"`
+}
+
+// Open loads the store of key from dir. A missing or unreadable store file
+// gives an empty store, since the cache can always be rebuilt.
+func Open[V any](dir, key string) *Store[V] {
+	s := &Store[V]{dir: dir, key: formatVersion + "-" + key, entries: make(map[string]V)}
+
+	data, err := os.ReadFile(s.path())
+	if err != nil {
+		return s
+	}
+	var f file[V]
+	if err := json.Unmarshal(data, &f); err == nil && f.Entries != nil {
+		s.entries = f.Entries
+	}
+	return s
+}
+
+func (s *Store[V]) path() string {
+	return filepath.Join(s.dir, filePrefix+s.key+".json")
+}
+
+// Get returns the value cached for the commit
+func (s *Store[V]) Get(hash string) (V, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	v, ok := s.entries[hash]
+	return v, ok
+}
+
+// Put caches the value of the commit
+func (s *Store[V]) Put(hash string, v V) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.entries[hash] = v
+	s.dirty = true
+}
+
+// Save writes the store to disk if it changed, and removes the stores of
+// other keys, which are stale
+func (s *Store[V]) Save() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if !s.dirty {
+		return nil
+	}
+
+	data, err := json.Marshal(file[V]{Entries: s.entries})
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(s.dir, 0755); err != nil {
+		return err
+	}
+
+	// Write atomically so concurrent runs never read a partial file
+	tmp, err := os.CreateTemp(s.dir, filePrefix+"*.tmp")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	if err := os.Rename(tmp.Name(), s.path()); err != nil {
+		return err
+	}
+	s.dirty = false
+
+	stale, _ := filepath.Glob(filepath.Join(s.dir, filePrefix+"*.json"))
+	for _, path := range stale {
+		if path != s.path() {
+			os.Remove(path)
+		}
+	}
+	return nil
+}
+
+// Clear removes dir and every store in it
+func Clear(dir string) error {
+	return os.RemoveAll(dir)
+}
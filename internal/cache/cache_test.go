@@ -0,0 +1,81 @@
+package cache
+
+import (
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+)
+
+func TestStore(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "sem-version")
+
+	s := Open[[]string](dir, "abc")
+	if _, ok := s.Get("1a2b"); ok {
+		t.Fatal("Get() found a value in a new store")
+	}
+	s.Put("1a2b", []string{"feat: a"})
+	if err := s.Save(); err != nil {
+		t.Fatalf("Save() error = %v", err)
+	}
+
+	reopened := Open[[]string](dir, "abc")
+	if v, ok := reopened.Get("1a2b"); !ok || len(v) != 1 || v[0] != "feat: a" {
+		t.Errorf("Get() = %v, %v after reopening", v, ok)
+	}
+
+	// A new key invalidates the stores of other keys once saved
+	other := Open[[]string](dir, "def")
+	if _, ok := other.Get("1a2b"); ok {
+		t.Error("Get() found a value stored under another key")
+	}
+	other.Put("3c4d", nil)
+	if err := other.Save(); err != nil {
+		t.Fatalf("Save() error = %v", err)
+	}
+	files, _ := filepath.Glob(filepath.Join(dir, "*"))
+	if len(files) != 1 {
+		t.Errorf("store files = %v, want only the current one", files)
+	}
+
+	if err := Clear(dir); err != nil {
+		t.Fatalf("Clear() error = %v", err)
+	}
+	if _, err := os.Stat(dir); !os.IsNotExist(err) {
+		t.Errorf("Clear() left %s", dir)
+	}
+}
+
+func TestStore_Corrupt(t *testing.T) {
+	dir := t.TempDir()
+	s := Open[int](dir, "k")
+	if err := os.WriteFile(s.path(), []byte("{not json"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	s = Open[int](dir, "k")
+	if _, ok := s.Get("x"); ok {
+		t.Error("Get() found a value in a corrupt store")
+	}
+	s.Put("x", 1)
+	if err := s.Save(); err != nil {
+		t.Fatalf("Save() error = %v", err)
+	}
+}
+
+func TestStore_Concurrent(t *testing.T) {
+	s := Open[int](t.TempDir(), "k")
+	var wg sync.WaitGroup
+	for i := range 20 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			s.Put(string(rune('a'+i)), i)
+			s.Get("a")
+		}()
+	}
+	wg.Wait()
+	if err := s.Save(); err != nil {
+		t.Fatalf("Save() error = %v", err)
+	}
+}
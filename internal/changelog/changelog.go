@@ -0,0 +1,95 @@
+package changelog
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/TheScenery/sem-version/pkg/parser"
+)
+
+// Entry is a commit to render in the changelog
+type Entry struct {
+	Hash    string
+	Message string
+}
+
+// section groups commits of the same kind under a heading
+type section struct {
+	title string
+	match func(parser.ParsedCommit) bool
+}
+
+// sections lists changelog sections in rendering order
+var sections = []section{
+	{"Breaking Changes", func(c parser.ParsedCommit) bool { return c.IsBreaking }},
+	{"Features", isType(parser.TypeFeat)},
+	{"Bug Fixes", isType(parser.TypeFix)},
+	{"Performance Improvements", isType(parser.TypePerf)},
+	{"Code Refactoring", isType(parser.TypeRefactor)},
+}
+
+func isType(t parser.CommitType) func(parser.ParsedCommit) bool {
+	return func(c parser.ParsedCommit) bool {
+		return !c.IsBreaking && c.Type == t
+	}
+}
+
+// Render returns a Markdown changelog section titled with the version
+// Commits not matching any section (docs, chore, ...) are left out
+func Render(title string, entries []Entry) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "## %s\n", title)
+
+	parsed := make([]parser.ParsedCommit, len(entries))
+	for i, e := range entries {
+		parsed[i] = parser.ParseCommit(e.Message)
+	}
+
+	empty := true
+	for _, s := range sections {
+		var lines []string
+		for i, c := range parsed {
+			if s.match(c) {
+				lines = append(lines, formatLine(c, entries[i].Hash))
+			}
+		}
+		if len(lines) == 0 {
+			continue
+		}
+
+		empty = false
+		fmt.Fprintf(&b, "\n### %s\n\n", s.title)
+		for _, l := range lines {
+			b.WriteString(l + "\n")
+		}
+	}
+
+	if empty {
+		b.WriteString("\nNo notable changes.\n")
+	}
+	return b.String()
+}
+
+// formatLine renders a single commit as a list item
+func formatLine(c parser.ParsedCommit, hash string) string {
+	desc := c.Description
+	if c.IsBreaking && c.BreakingChange != "" {
+		desc = c.BreakingChange
+	}
+	if desc == "" {
+		desc = strings.SplitN(c.RawMessage, "\n", 2)[0]
+	}
+
+	line := "- "
+	if c.Scope != "" {
+		line += "**" + c.Scope + ":** "
+	}
+	line += desc
+	if len(hash) > 7 {
+		hash = hash[:7]
+	}
+	if hash != "" {
+		line += " (" + hash + ")"
+	}
+	return line
+}
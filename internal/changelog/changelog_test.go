@@ -0,0 +1,52 @@
+package changelog
+
+import (
+	"testing"
+)
+
+func TestRender(t *testing.T) {
+	tests := []struct {
+		name    string
+		entries []Entry
+		want    string
+	}{
+		{
+			name: "grouped sections",
+			entries: []Entry{
+				{Hash: "1111111aaaa", Message: "fix(core): handle nil config"},
+				{Hash: "2222222bbbb", Message: "feat: add login"},
+				{Hash: "3333333cccc", Message: "docs: update readme"},
+				{Hash: "4444444dddd", Message: "feat(api)!: drop v1 endpoints"},
+				{Hash: "5555555eeee", Message: "refactor: split parser\n\nBREAKING CHANGE: Parse was renamed"},
+			},
+			want: `## v2.0.0
+
+### Breaking Changes
+
+- **api:** drop v1 endpoints (4444444)
+- Parse was renamed (5555555)
+
+### Features
+
+- add login (2222222)
+
+### Bug Fixes
+
+- **core:** handle nil config (1111111)
+`,
+		},
+		{
+			name:    "no notable changes",
+			entries: []Entry{{Hash: "abc", Message: "chore: bump deps"}},
+			want:    "## v2.0.0\n\nNo notable changes.\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Render("v2.0.0", tt.entries); got != tt.want {
+				t.Errorf("Render() =\n%s\nwant\n%s", got, tt.want)
+			}
+		})
+	}
+}
@@ -1,15 +1,32 @@
 package config
 
 import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
 	"os"
 	"path/filepath"
+	"reflect"
 	"regexp"
+	"regexp/syntax"
+	"slices"
+	"strings"
 
+	"github.com/TheScenery/sem-version/internal/pathfilter"
+	"github.com/TheScenery/sem-version/pkg/version"
 	"gopkg.in/yaml.v3"
 )
 
 // Config represents the configuration for commit parsing
 type Config struct {
+	// Extends names a config file, relative to this one, or a built-in
+	// preset whose settings this config inherits (optional)
+	Extends string `yaml:"extends"`
+	// Replace lists the inherited list sections this config replaces
+	// instead of merging with, e.g. [minor, branches]
+	Replace []string `yaml:"replace"`
+
 	// Major version bump patterns (e.g., breaking changes)
 	Major []string `yaml:"major"`
 	// Minor version bump patterns (e.g., new features)
@@ -17,12 +34,194 @@ type Config struct {
 	// Patch version bump patterns (e.g., bug fixes)
 	Patch []string `yaml:"patch"`
 
+	// Scheme selects the versioning scheme: "semver" (default) or "calver"
+	Scheme string `yaml:"scheme"`
+	// CalVerFormat is the calver format (default: YYYY.MM.MICRO)
+	CalVerFormat string `yaml:"calver_format"`
+
+	// History selects the analyzed commits: "all" (default) or
+	// "first-parent" to skip the commits of merged branches
+	History string `yaml:"history"`
+	// MergeCommits selects how merge commits are classified: "message"
+	// (default) or "pull-request" to use only the PR title and body
+	MergeCommits string `yaml:"merge_commits"`
+	// SquashCommits selects how squash commits are classified: "message"
+	// (default) or "expand" to classify each commit listed in the body
+	SquashCommits string `yaml:"squash_commits"`
+
+	// IncludePaths lists globs of the files whose changes count towards the
+	// bump (default: all files)
+	IncludePaths []string `yaml:"include_paths"`
+	// ExcludePaths lists globs of the files whose changes are ignored, e.g.
+	// "docs/" or "**/*_test.go"
+	ExcludePaths []string `yaml:"exclude_paths"`
+
+	// Branches lists release policies, the first rule matching the current
+	// branch applies (optional)
+	Branches []Branch `yaml:"branches"`
+
+	// Packages declares the packages of a monorepo (optional)
+	Packages []Package `yaml:"packages"`
+	// Scopes maps conventional commit scopes to package names or paths
+	Scopes map[string]string `yaml:"scopes"`
+	// Unscoped selects how commits without a mapped scope are routed:
+	// "paths" (default), "all" or "none"
+	Unscoped string `yaml:"unscoped"`
+
+	// BumpFiles lists project files rewritten with the computed version
+	BumpFiles []BumpFile `yaml:"bump_files"`
+
+	// Tests lists sample commit messages with their expected bump, checked
+	// by `sem-version config test`
+	Tests []Test `yaml:"tests"`
+
+	// source is the file the config was loaded from, nil for configs built
+	// in code
+	source *source
+
 	// Compiled regexes (internal use)
 	majorRegexes []*regexp.Regexp
 	minorRegexes []*regexp.Regexp
 	patchRegexes []*regexp.Regexp
 }
 
+// Branch is a release policy applied to matching branches
+type Branch struct {
+	// Name is a glob matched against the branch name, * matches any characters
+	Name string `yaml:"name"`
+	// Prerelease is the prerelease identifier of versions released from the
+	// branch, {{branch}} is replaced by the sanitized branch name.
+	// Empty for stable releases.
+	Prerelease string `yaml:"prerelease"`
+	// Max is an exclusive upper bound of versions released from the branch
+	Max string `yaml:"max"`
+	// Range is a constraint versions released from the branch must satisfy,
+	// e.g. ">=1.4.0 <1.5.0", "~1.4" or "1.x"
+	Range string `yaml:"range"`
+	// OnViolation selects what happens when the computed version is out of
+	// range or already tagged: "error" (default) or "patch" to retry with a
+	// patch bump
+	OnViolation string `yaml:"on_violation"`
+}
+
+// Violation handling modes of branch policies
+const (
+	ViolationError = "error"
+	ViolationPatch = "patch"
+)
+
+// Constraint returns the range allowed by Range and Max, or nil if the
+// branch is unconstrained
+func (b Branch) Constraint() (*version.Constraint, error) {
+	if b.Range == "" && b.Max == "" {
+		return nil, nil
+	}
+
+	// Max applies to every ||-separated alternative of Range
+	sets := strings.Split(b.Range, "||")
+	if b.Max != "" {
+		for i := range sets {
+			sets[i] += " <" + b.Max
+		}
+	}
+
+	c, err := version.ParseConstraint(strings.Join(sets, "||"))
+	if err != nil {
+		return nil, err
+	}
+	return &c, nil
+}
+
+// Matches reports whether the rule applies to the branch
+func (b Branch) Matches(branch string) bool {
+	pattern := regexp.QuoteMeta(b.Name)
+	pattern = strings.ReplaceAll(pattern, `\*`, ".*")
+	pattern = strings.ReplaceAll(pattern, `\?`, ".")
+	matched, _ := regexp.MatchString("^"+pattern+"$", branch)
+	return matched
+}
+
+// PrereleaseFor returns the prerelease identifier for the branch
+func (b Branch) PrereleaseFor(branch string) string {
+	return strings.ReplaceAll(b.Prerelease, "{{branch}}", version.SanitizeIdentifier(branch))
+}
+
+// Package represents a single versioned package inside a monorepo
+type Package struct {
+	// Name identifies the package in output and dependency declarations
+	Name string `yaml:"name"`
+	// Path is the package directory relative to the repository root
+	Path string `yaml:"path"`
+	// TagPrefix is prepended to version tags (default: "<name>/")
+	TagPrefix string `yaml:"tag_prefix"`
+	// DependsOn lists names of sibling packages this package depends on
+	DependsOn []string `yaml:"depends_on"`
+	// BumpFiles lists files, relative to Path, rewritten with the package version
+	BumpFiles []BumpFile `yaml:"bump_files"`
+}
+
+// BumpFile describes a project file that receives the computed version
+type BumpFile struct {
+	// Path of the file to rewrite
+	Path string `yaml:"path"`
+	// Format is one of json, yaml, toml or regex
+	Format string `yaml:"format"`
+	// Key is the dotted path of the version value (json, yaml, toml)
+	Key string `yaml:"key"`
+	// Pattern is a regex whose first capture group is replaced (regex)
+	Pattern string `yaml:"pattern"`
+}
+
+// Supported bump file formats
+const (
+	FormatJSON  = "json"
+	FormatYAML  = "yaml"
+	FormatTOML  = "toml"
+	FormatRegex = "regex"
+)
+
+// Versioning schemes
+const (
+	SchemeSemVer = "semver"
+	SchemeCalVer = "calver"
+)
+
+// DefaultCalVerFormat is the calver format used when none is configured
+const DefaultCalVerFormat = "YYYY.MM.MICRO"
+
+// History modes
+const (
+	HistoryAll         = "all"
+	HistoryFirstParent = "first-parent"
+)
+
+// Merge commit classification modes
+const (
+	MergeMessage     = "message"
+	MergePullRequest = "pull-request"
+)
+
+// Squash commit classification modes
+const (
+	SquashMessage = "message"
+	SquashExpand  = "expand"
+)
+
+// Routing modes for commits without a mapped scope
+const (
+	UnscopedPaths = "paths"
+	UnscopedAll   = "all"
+	UnscopedNone  = "none"
+)
+
+// GetTagPrefix returns the tag prefix for the package
+func (p Package) GetTagPrefix() string {
+	if p.TagPrefix != "" {
+		return p.TagPrefix
+	}
+	return p.Name + "/"
+}
+
 // DefaultConfig returns the default configuration based on Conventional Commits
 func DefaultConfig() *Config {
 	return &Config{
@@ -71,23 +270,93 @@ patch:
 `
 }
 
-// Load loads configuration from a file
+// Load loads configuration from a file, merged with the configs it extends
+// Unknown keys are rejected, and invalid settings are reported as an *Error
+// with their position in the file declaring them.
 func Load(path string) (*Config, error) {
-	data, err := os.ReadFile(path)
+	cfg, err := loadFile(path, nil)
 	if err != nil {
 		return nil, err
 	}
 
-	var cfg Config
-	if err := yaml.Unmarshal(data, &cfg); err != nil {
+	if err := cfg.validate(); err != nil {
+		return nil, cfg.source.locate(err)
+	}
+	return cfg, nil
+}
+
+// loadFile decodes a config file and merges it with the configs it extends,
+// chain lists the files extending it
+func loadFile(path string, chain []string) (*Config, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
 		return nil, err
 	}
 
-	if err := cfg.compile(); err != nil {
+	var root yaml.Node
+	if err := yaml.Unmarshal(data, &root); err != nil {
+		return nil, yamlError(path, err)
+	}
+	if err := checkNode(path, &root, reflect.TypeOf(Config{}), nil); err != nil {
 		return nil, err
 	}
 
-	return &cfg, nil
+	cfg := &Config{source: &source{file: path, root: &root}}
+	if len(root.Content) > 0 {
+		if err := root.Decode(cfg); err != nil {
+			return nil, yamlError(path, err)
+		}
+	}
+
+	if cfg.Extends == "" {
+		return cfg, nil
+	}
+	base, err := cfg.loadBase(append(chain, path))
+	if err != nil {
+		return nil, cfg.source.locate(err)
+	}
+	merged, err := merge(base, cfg)
+	if err != nil {
+		return nil, cfg.source.locate(err)
+	}
+	return merged, nil
+}
+
+// validate compiles the patterns and checks every setting
+func (c *Config) validate() error {
+	if len(c.Replace) > 0 && c.Extends == "" {
+		return errorAt(fieldPath{"replace"}, "requires extends")
+	}
+
+	if err := c.Compile(); err != nil {
+		return err
+	}
+
+	if err := c.validatePackages(); err != nil {
+		return err
+	}
+
+	if err := c.validateBumpFiles(); err != nil {
+		return err
+	}
+
+	if _, err := c.VersionScheme(); err != nil {
+		return err
+	}
+
+	if err := c.validateHistory(); err != nil {
+		return err
+	}
+
+	if err := c.validatePaths(); err != nil {
+		return err
+	}
+
+	if err := c.validateTests(); err != nil {
+		return err
+	}
+
+	return c.validateBranches()
 }
 
 // LoadDefault looks for .sem-version.yaml or .sem-version.yml in the given directory
@@ -104,27 +373,28 @@ func LoadDefault(dir string) (*Config, error) {
 
 	// No config file found, use default
 	cfg := DefaultConfig()
-	if err := cfg.compile(); err != nil {
+	if err := cfg.Compile(); err != nil {
 		return nil, err
 	}
 	return cfg, nil
 }
 
-// compile compiles all regex patterns
-func (c *Config) compile() error {
+// Compile compiles all regex patterns
+// Load and LoadDefault compile the config, Compile is needed for configs built in code
+func (c *Config) Compile() error {
 	var err error
 
-	c.majorRegexes, err = compilePatterns(c.Major)
+	c.majorRegexes, err = compilePatterns("major", c.Major)
 	if err != nil {
 		return err
 	}
 
-	c.minorRegexes, err = compilePatterns(c.Minor)
+	c.minorRegexes, err = compilePatterns("minor", c.Minor)
 	if err != nil {
 		return err
 	}
 
-	c.patchRegexes, err = compilePatterns(c.Patch)
+	c.patchRegexes, err = compilePatterns("patch", c.Patch)
 	if err != nil {
 		return err
 	}
@@ -132,18 +402,244 @@ func (c *Config) compile() error {
 	return nil
 }
 
-func compilePatterns(patterns []string) ([]*regexp.Regexp, error) {
+// validatePackages checks that package declarations are complete and consistent
+func (c *Config) validatePackages() error {
+	names := make(map[string]bool, len(c.Packages))
+	for i, p := range c.Packages {
+		if p.Name == "" {
+			return errorAt(fieldPath{"packages", i}, "name is required")
+		}
+		if p.Path == "" {
+			return errorAt(fieldPath{"packages", i}, "path of package %s is required", p.Name)
+		}
+		if names[p.Name] {
+			return errorAt(fieldPath{"packages", i, "name"}, "package %s declared more than once", p.Name)
+		}
+		names[p.Name] = true
+	}
+
+	for i, p := range c.Packages {
+		for j, dep := range p.DependsOn {
+			if !names[dep] {
+				return errorAt(fieldPath{"packages", i, "depends_on", j}, "unknown dependency %s", dep)
+			}
+		}
+	}
+
+	scopes := make([]string, 0, len(c.Scopes))
+	for scope := range c.Scopes {
+		scopes = append(scopes, scope)
+	}
+	slices.Sort(scopes)
+	for _, scope := range scopes {
+		if _, ok := c.ScopePackage(scope); !ok {
+			return errorAt(fieldPath{"scopes", scope}, "unknown package %s", c.Scopes[scope])
+		}
+	}
+
+	switch c.Unscoped {
+	case "", UnscopedPaths, UnscopedAll, UnscopedNone:
+	default:
+		return errorAt(fieldPath{"unscoped"}, "invalid value %q (want paths, all or none)", c.Unscoped)
+	}
+
+	return nil
+}
+
+// VersionScheme returns the versioning scheme selected by the config
+func (c *Config) VersionScheme() (version.Scheme, error) {
+	switch c.Scheme {
+	case "", SchemeSemVer:
+		return version.SemVer{}, nil
+	case SchemeCalVer:
+		format := c.CalVerFormat
+		if format == "" {
+			format = DefaultCalVerFormat
+		}
+		scheme, err := version.NewCalVer(format)
+		if err != nil {
+			return nil, errorAt(fieldPath{"calver_format"}, "%w", err)
+		}
+		return scheme, nil
+	default:
+		return nil, errorAt(fieldPath{"scheme"}, "invalid value %q (want semver or calver)", c.Scheme)
+	}
+}
+
+// validateHistory checks the history traversal and merge/squash settings
+func (c *Config) validateHistory() error {
+	switch c.History {
+	case "", HistoryAll, HistoryFirstParent:
+	default:
+		return errorAt(fieldPath{"history"}, "invalid value %q (want all or first-parent)", c.History)
+	}
+	switch c.MergeCommits {
+	case "", MergeMessage, MergePullRequest:
+	default:
+		return errorAt(fieldPath{"merge_commits"}, "invalid value %q (want message or pull-request)", c.MergeCommits)
+	}
+	switch c.SquashCommits {
+	case "", SquashMessage, SquashExpand:
+	default:
+		return errorAt(fieldPath{"squash_commits"}, "invalid value %q (want message or expand)", c.SquashCommits)
+	}
+	return nil
+}
+
+// ClassificationKey returns a digest of the settings affecting how a commit
+// is classified, identifying the cached classifications made with them
+func (c *Config) ClassificationKey() string {
+	data, _ := json.Marshal([]any{c.Major, c.Minor, c.Patch, c.MergeCommits, c.SquashCommits})
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:8])
+}
+
+// validatePaths checks the include and exclude globs one by one
+func (c *Config) validatePaths() error {
+	sections := []struct {
+		name  string
+		globs []string
+	}{{"include_paths", c.IncludePaths}, {"exclude_paths", c.ExcludePaths}}
+	for _, section := range sections {
+		for i, glob := range section.globs {
+			if err := pathfilter.ValidateGlob(glob); err != nil {
+				return errorAt(fieldPath{section.name, i}, "%w", err)
+			}
+		}
+	}
+	return nil
+}
+
+// PathFilter returns the filter built from IncludePaths and ExcludePaths
+func (c *Config) PathFilter() (*pathfilter.Filter, error) {
+	return pathfilter.New(c.IncludePaths, c.ExcludePaths)
+}
+
+// BranchPolicy returns the first branch rule matching the branch
+func (c *Config) BranchPolicy(branch string) (Branch, bool) {
+	for _, b := range c.Branches {
+		if b.Matches(branch) {
+			return b, true
+		}
+	}
+	return Branch{}, false
+}
+
+// validateBranches checks the branch release policies
+func (c *Config) validateBranches() error {
+	for i, b := range c.Branches {
+		if b.Name == "" {
+			return errorAt(fieldPath{"branches", i}, "name is required")
+		}
+		if b.Prerelease != "" {
+			if err := version.ValidatePrerelease(b.PrereleaseFor("branch")); err != nil {
+				return errorAt(fieldPath{"branches", i, "prerelease"}, "%w", err)
+			}
+		}
+		if b.Max != "" {
+			if _, err := version.Parse(b.Max); err != nil {
+				return errorAt(fieldPath{"branches", i, "max"}, "%w", err)
+			}
+		}
+		if _, err := b.Constraint(); err != nil {
+			return errorAt(fieldPath{"branches", i, "range"}, "%w", err)
+		}
+		switch b.OnViolation {
+		case "", ViolationError, ViolationPatch:
+		default:
+			return errorAt(fieldPath{"branches", i, "on_violation"}, "invalid value %q (want error or patch)", b.OnViolation)
+		}
+	}
+	return nil
+}
+
+// validateBumpFiles checks the bump file declarations of the config and its packages
+func (c *Config) validateBumpFiles() error {
+	if err := validateBumpFiles(fieldPath{"bump_files"}, c.BumpFiles); err != nil {
+		return err
+	}
+	for i, p := range c.Packages {
+		if err := validateBumpFiles(fieldPath{"packages", i, "bump_files"}, p.BumpFiles); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// validateBumpFiles checks the bump files listed at keys
+func validateBumpFiles(keys fieldPath, files []BumpFile) error {
+	for i, f := range files {
+		at := append(keys[:len(keys):len(keys)], i)
+		if f.Path == "" {
+			return errorAt(at, "path is required")
+		}
+		switch f.Format {
+		case FormatJSON, FormatYAML, FormatTOML:
+			if f.Key == "" {
+				return errorAt(at, "key is required for format %s", f.Format)
+			}
+		case FormatRegex:
+			re, err := regexp.Compile(f.Pattern)
+			if err != nil {
+				return errorAt(append(at, "pattern"), "invalid pattern `%s`: %w", f.Pattern, err)
+			}
+			if re.NumSubexp() == 0 {
+				return errorAt(append(at, "pattern"), "pattern needs a capture group for the version")
+			}
+		default:
+			return errorAt(append(at, "format"), "unknown format %q (want json, yaml, toml or regex)", f.Format)
+		}
+	}
+	return nil
+}
+
+// ScopePackage returns the name of the package a commit scope is mapped to
+func (c *Config) ScopePackage(scope string) (string, bool) {
+	target, ok := c.Scopes[scope]
+	if !ok {
+		return "", false
+	}
+	for _, p := range c.Packages {
+		if p.Name == target || filepath.Clean(p.Path) == filepath.Clean(target) {
+			return p.Name, true
+		}
+	}
+	return "", false
+}
+
+// compilePatterns compiles the patterns of a section, naming the invalid one
+func compilePatterns(section string, patterns []string) ([]*regexp.Regexp, error) {
 	regexes := make([]*regexp.Regexp, 0, len(patterns))
-	for _, p := range patterns {
+	for i, p := range patterns {
 		re, err := regexp.Compile(p)
 		if err != nil {
-			return nil, err
+			reason := err.Error()
+			var syntaxErr *syntax.Error
+			if errors.As(err, &syntaxErr) {
+				reason = syntaxErr.Code.String()
+			}
+			return nil, errorAt(fieldPath{section, i}, "invalid pattern `%s`: %s", p, reason)
 		}
 		regexes = append(regexes, re)
 	}
 	return regexes, nil
 }
 
+// Classify returns the bump type of a commit message, checking patterns in
+// order of priority: major > minor > patch
+func (c *Config) Classify(message string) version.BumpType {
+	switch {
+	case c.MatchMajor(message):
+		return version.BumpMajorType
+	case c.MatchMinor(message):
+		return version.BumpMinorType
+	case c.MatchPatch(message):
+		return version.BumpPatchType
+	default:
+		return version.BumpNone
+	}
+}
+
 // MatchMajor returns true if the message matches any major bump pattern
 func (c *Config) MatchMajor(message string) bool {
 	for _, re := range c.majorRegexes {
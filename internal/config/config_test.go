@@ -4,11 +4,13 @@ import (
 	"os"
 	"path/filepath"
 	"testing"
+
+	"github.com/TheScenery/sem-version/pkg/version"
 )
 
 func TestDefaultConfig(t *testing.T) {
 	cfg := DefaultConfig()
-	if err := cfg.compile(); err != nil {
+	if err := cfg.Compile(); err != nil {
 		t.Fatalf("Failed to compile default config: %v", err)
 	}
 
@@ -108,3 +110,88 @@ func TestLoadDefault(t *testing.T) {
 		t.Error("Expected default config to match 'feat: something'")
 	}
 }
+
+func TestClassify(t *testing.T) {
+	cfg, err := LoadDefault(t.TempDir())
+	if err != nil {
+		t.Fatalf("Failed to load default config: %v", err)
+	}
+
+	tests := []struct {
+		message string
+		want    version.BumpType
+	}{
+		{"feat!: breaking change", version.BumpMajorType},
+		{"feat: add\n\nBREAKING CHANGE: removed", version.BumpMajorType},
+		{"feat: new feature", version.BumpMinorType},
+		{"fix: bug fix", version.BumpPatchType},
+		{"docs: update readme", version.BumpNone},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.message, func(t *testing.T) {
+			if got := cfg.Classify(tt.message); got != tt.want {
+				t.Errorf("Classify() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestBranch_Constraint(t *testing.T) {
+	tests := []struct {
+		branch  Branch
+		version string
+		want    bool
+	}{
+		{Branch{Range: "~1.4"}, "1.4.3", true},
+		{Branch{Range: "~1.4"}, "1.5.0", false},
+		{Branch{Max: "2.0.0"}, "1.9.0", true},
+		{Branch{Max: "2.0.0"}, "2.0.0", false},
+		{Branch{Range: "0.x || 1.x", Max: "1.5.0"}, "0.9.0", true},
+		{Branch{Range: "0.x || 1.x", Max: "1.5.0"}, "1.5.0", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.branch.Range+" "+tt.branch.Max+" "+tt.version, func(t *testing.T) {
+			c, err := tt.branch.Constraint()
+			if err != nil {
+				t.Fatalf("Constraint() error = %v", err)
+			}
+			v, err := version.Parse(tt.version)
+			if err != nil {
+				t.Fatalf("Parse() error = %v", err)
+			}
+			if got := c.Check(v); got != tt.want {
+				t.Errorf("Check(%s) = %v, want %v", tt.version, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestLoad_InvalidHistory(t *testing.T) {
+	for _, content := range []string{"history: linear\n", "merge_commits: title\n", "squash_commits: split\n"} {
+		t.Run(content, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), ".sem-version.yaml")
+			if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+				t.Fatalf("Failed to write config: %v", err)
+			}
+			if _, err := Load(path); err == nil {
+				t.Error("Load() expected error")
+			}
+		})
+	}
+}
+
+func TestClassificationKey(t *testing.T) {
+	a := DefaultConfig()
+	b := DefaultConfig()
+	b.ExcludePaths = []string{"docs/"}
+	if a.ClassificationKey() != b.ClassificationKey() {
+		t.Error("ClassificationKey() changed with a setting unrelated to classification")
+	}
+
+	b.Minor = append(b.Minor, `^new:`)
+	if a.ClassificationKey() == b.ClassificationKey() {
+		t.Error("ClassificationKey() did not change with the patterns")
+	}
+}
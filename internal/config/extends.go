@@ -0,0 +1,214 @@
+package config
+
+import (
+	"errors"
+	"maps"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+)
+
+// presets are the built-in configs that can be extended by name
+var presets = map[string]func() *Config{
+	// conventional is the default config, for Conventional Commits
+	"conventional": DefaultConfig,
+	// pull-requests versions the main line of a pull request workflow
+	"pull-requests": func() *Config {
+		c := DefaultConfig()
+		c.History = HistoryFirstParent
+		c.MergeCommits = MergePullRequest
+		c.SquashCommits = SquashExpand
+		return c
+	},
+}
+
+// presetNames returns the names of the built-in presets
+func presetNames() []string {
+	return slices.Sorted(maps.Keys(presets))
+}
+
+// isPreset reports whether extends names a preset rather than a file:
+// preset names contain neither a slash nor a dot
+func isPreset(extends string) bool {
+	return !strings.ContainsAny(extends, `/\.`)
+}
+
+// loadBase loads the config named by Extends, chain lists the files
+// extending it
+func (c *Config) loadBase(chain []string) (*Config, error) {
+	at := fieldPath{"extends"}
+
+	if isPreset(c.Extends) {
+		preset, ok := presets[c.Extends]
+		if !ok {
+			return nil, errorAt(at, "unknown preset %q (want %s, or a path such as ./%s.yaml)",
+				c.Extends, strings.Join(presetNames(), " or "), c.Extends)
+		}
+		base := preset()
+		base.source = &source{name: "preset " + c.Extends}
+		return base, nil
+	}
+
+	path := c.Extends
+	if !filepath.IsAbs(path) {
+		path = filepath.Join(filepath.Dir(chain[len(chain)-1]), path)
+	}
+	for _, p := range chain {
+		if sameFile(p, path) {
+			return nil, errorAt(at, "cycle: %s", strings.Join(append(chain, path), " -> "))
+		}
+	}
+
+	base, err := loadFile(path, chain)
+	var cfgErr *Error
+	if err != nil && !errors.As(err, &cfgErr) {
+		// The file cannot be read
+		return nil, errorAt(at, "%w", err)
+	}
+	return base, err
+}
+
+// sameFile reports whether two paths name the same config file
+func sameFile(a, b string) bool {
+	if filepath.Clean(a) == filepath.Clean(b) {
+		return true
+	}
+	ai, err := os.Stat(a)
+	if err != nil {
+		return false
+	}
+	bi, err := os.Stat(b)
+	return err == nil && os.SameFile(ai, bi)
+}
+
+// Merge modes of list sections
+const (
+	// appendMode lists the inherited elements first
+	appendMode = "append"
+	// prependMode lists the elements of the extending config first, so
+	// that its first-match rules take precedence
+	prependMode = "prepend"
+)
+
+// listSections are the sections merged element by element
+var listSections = map[string]string{
+	"major":         appendMode,
+	"minor":         appendMode,
+	"patch":         appendMode,
+	"include_paths": appendMode,
+	"exclude_paths": appendMode,
+	"branches":      prependMode,
+	"packages":      appendMode,
+	"bump_files":    appendMode,
+	"tests":         appendMode,
+}
+
+// merger merges a config with the config it extends, recording where each
+// merged setting is declared
+type merger struct {
+	base, child *Config
+	replace     map[string]bool
+	src         *source
+}
+
+// merge returns the settings of child merged into base:
+//   - single values of child override those of base
+//   - list sections are appended to those of base, except branches which
+//     are prepended, and sections listed in Replace which replace them
+//   - scopes are merged key by key, unless listed in Replace
+func merge(base, child *Config) (*Config, error) {
+	m := &merger{
+		base:    base,
+		child:   child,
+		replace: make(map[string]bool),
+		src: &source{
+			file:      child.source.file,
+			root:      child.source.root,
+			inherited: make(map[string]origin),
+			elements:  make(map[string][]origin),
+		},
+	}
+	for i, section := range child.Replace {
+		if _, ok := listSections[section]; !ok && section != "scopes" {
+			return nil, errorAt(fieldPath{"replace", i}, "%s cannot be replaced (want a list section or scopes)", section)
+		}
+		m.replace[section] = true
+	}
+
+	merged := *child
+	merged.source = m.src
+
+	merged.Major = mergeList(m, "major", base.Major, child.Major)
+	merged.Minor = mergeList(m, "minor", base.Minor, child.Minor)
+	merged.Patch = mergeList(m, "patch", base.Patch, child.Patch)
+	merged.IncludePaths = mergeList(m, "include_paths", base.IncludePaths, child.IncludePaths)
+	merged.ExcludePaths = mergeList(m, "exclude_paths", base.ExcludePaths, child.ExcludePaths)
+	merged.Branches = mergeList(m, "branches", base.Branches, child.Branches)
+	merged.Packages = mergeList(m, "packages", base.Packages, child.Packages)
+	merged.BumpFiles = mergeList(m, "bump_files", base.BumpFiles, child.BumpFiles)
+	merged.Tests = mergeList(m, "tests", base.Tests, child.Tests)
+
+	merged.Scheme = m.value("scheme", base.Scheme, child.Scheme)
+	merged.CalVerFormat = m.value("calver_format", base.CalVerFormat, child.CalVerFormat)
+	merged.History = m.value("history", base.History, child.History)
+	merged.MergeCommits = m.value("merge_commits", base.MergeCommits, child.MergeCommits)
+	merged.SquashCommits = m.value("squash_commits", base.SquashCommits, child.SquashCommits)
+	merged.Unscoped = m.value("unscoped", base.Unscoped, child.Unscoped)
+
+	merged.Scopes = child.Scopes
+	if !m.replace["scopes"] && len(base.Scopes) > 0 {
+		merged.Scopes = maps.Clone(base.Scopes)
+		for scope, target := range child.Scopes {
+			merged.Scopes[scope] = target
+		}
+		for scope := range base.Scopes {
+			if _, ok := child.Scopes[scope]; !ok {
+				m.src.inherited[fieldPath{"scopes", scope}.String()] = origin{src: base.source}
+			}
+		}
+	}
+
+	return &merged, nil
+}
+
+// mergeList merges the elements of a list section
+func mergeList[T any](m *merger, section string, base, child []T) []T {
+	baseElems := elements(m.base.source, section, len(base))
+	childElems := elements(m.child.source, section, len(child))
+
+	switch {
+	case m.replace[section]:
+		m.src.elements[section] = childElems
+		return child
+	case listSections[section] == prependMode:
+		m.src.elements[section] = append(childElems, baseElems...)
+		return append(slices.Clip(child), base...)
+	default:
+		m.src.elements[section] = append(baseElems, childElems...)
+		return append(slices.Clip(base), child...)
+	}
+}
+
+// elements returns the origins of the n elements of a list section
+func elements(src *source, section string, n int) []origin {
+	if elems, ok := src.elements[section]; ok {
+		return slices.Clip(elems)
+	}
+	elems := make([]origin, n)
+	for i := range elems {
+		elems[i] = origin{src: src, index: i}
+	}
+	return elems
+}
+
+// value returns the value of a single value setting
+func (m *merger) value(section, base, child string) string {
+	if child != "" {
+		return child
+	}
+	if base != "" {
+		m.src.inherited[section] = origin{src: m.base.source}
+	}
+	return base
+}
@@ -0,0 +1,175 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+)
+
+// writeConfigs writes the config files into a temporary directory and
+// returns the directory
+func writeConfigs(t *testing.T, files map[string]string) string {
+	t.Helper()
+	dir := t.TempDir()
+	for name, content := range files {
+		path := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatalf("Failed to create directory: %v", err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatalf("Failed to write config: %v", err)
+		}
+	}
+	return dir
+}
+
+func TestLoad_Extends(t *testing.T) {
+	dir := writeConfigs(t, map[string]string{
+		"shared/base.yaml": `minor: ['^feat:']
+patch: ['^bugfix:']
+history: first-parent
+branches:
+  - name: main
+`,
+		"repo/.sem-version.yaml": `extends: ../shared/base.yaml
+replace: [patch]
+minor: ['^add:']
+patch: ['^fix:']
+branches:
+  - name: next
+    prerelease: next
+`,
+	})
+
+	cfg, err := Load(filepath.Join(dir, "repo/.sem-version.yaml"))
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	if want := []string{"^feat:", "^add:"}; !slices.Equal(cfg.Minor, want) {
+		t.Errorf("Minor = %v, want %v", cfg.Minor, want)
+	}
+	if want := []string{"^fix:"}; !slices.Equal(cfg.Patch, want) {
+		t.Errorf("Patch = %v, want %v", cfg.Patch, want)
+	}
+	if cfg.History != HistoryFirstParent {
+		t.Errorf("History = %q, want %q", cfg.History, HistoryFirstParent)
+	}
+	var branches []string
+	for _, b := range cfg.Branches {
+		branches = append(branches, b.Name)
+	}
+	if want := []string{"next", "main"}; !slices.Equal(branches, want) {
+		t.Errorf("Branches = %v, want %v", branches, want)
+	}
+	if !cfg.MatchMinor("feat: x") || !cfg.MatchMinor("add: x") || cfg.MatchPatch("bugfix: x") {
+		t.Error("merged patterns are not compiled")
+	}
+}
+
+func TestLoad_ExtendsPreset(t *testing.T) {
+	dir := writeConfigs(t, map[string]string{
+		".sem-version.yaml": "extends: pull-requests\nminor: ['^add:']\n",
+	})
+
+	cfg, err := Load(filepath.Join(dir, ".sem-version.yaml"))
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	want := append(DefaultConfig().Minor, "^add:")
+	if !slices.Equal(cfg.Minor, want) {
+		t.Errorf("Minor = %v, want %v", cfg.Minor, want)
+	}
+	if cfg.MergeCommits != MergePullRequest || len(cfg.Major) != len(DefaultConfig().Major) {
+		t.Errorf("preset settings not inherited: %+v", cfg)
+	}
+	if warnings := cfg.Lint(); len(warnings) != 0 {
+		t.Errorf("Lint() = %v, want no warnings", warnings)
+	}
+}
+
+func TestLoad_ExtendsErrors(t *testing.T) {
+	tests := []struct {
+		name  string
+		files map[string]string
+		want  string
+	}{
+		{
+			name:  "unknown preset",
+			files: map[string]string{"a.yaml": "minor: []\nextends: angular\n"},
+			want:  `a.yaml:2:10: extends: unknown preset "angular"`,
+		},
+		{
+			name:  "missing file",
+			files: map[string]string{"a.yaml": "extends: ./missing.yaml\n"},
+			want:  "a.yaml:1:10: extends: open ",
+		},
+		{
+			name:  "cycle",
+			files: map[string]string{"a.yaml": "extends: b.yaml\n", "b.yaml": "extends: ./a.yaml\n"},
+			want:  "b.yaml:1:10: extends: cycle: ",
+		},
+		{
+			name:  "replace single value",
+			files: map[string]string{"a.yaml": "extends: conventional\nreplace: [minor, history]\n"},
+			want:  "a.yaml:2:18: replace[1]: history cannot be replaced",
+		},
+		{
+			name:  "replace without extends",
+			files: map[string]string{"a.yaml": "replace: [minor]\n"},
+			want:  "a.yaml:1:10: replace: requires extends",
+		},
+		{
+			name:  "error in base",
+			files: map[string]string{"a.yaml": "extends: b.yaml\n", "b.yaml": "minor:\n  - '^feat:'\n  - '('\n"},
+			want:  "b.yaml:3:5: minor[1]: invalid pattern `(`",
+		},
+		{
+			name: "merged element of base",
+			files: map[string]string{
+				"a.yaml": "extends: b.yaml\nbump_files:\n  - path: VERSION\n    format: regex\n    pattern: '(.*)'\n",
+				"b.yaml": "bump_files:\n  - path: package.json\n    format: json\n",
+			},
+			want: "b.yaml:2:5: bump_files[0]: key is required",
+		},
+		{
+			name: "merged element of child",
+			files: map[string]string{
+				"a.yaml": "extends: b.yaml\nbranches:\n  - name: next\n    max: '1'\n",
+				"b.yaml": "branches:\n  - name: main\n",
+			},
+			want: "a.yaml:4:10: branches[0].max: ",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := writeConfigs(t, tt.files)
+			_, err := Load(filepath.Join(dir, "a.yaml"))
+			if err == nil {
+				t.Fatal("Load() expected error")
+			}
+			if want := filepath.Join(dir, tt.want); !strings.HasPrefix(err.Error(), want) {
+				t.Errorf("Load() error = %q, want prefix %q", err, want)
+			}
+		})
+	}
+}
+
+func TestLint_Extends(t *testing.T) {
+	dir := writeConfigs(t, map[string]string{
+		"a.yaml": "extends: b.yaml\nminor:\n  - '^feat:'\n",
+		"b.yaml": "minor:\n  - '^feat:'\n",
+	})
+	cfg, err := Load(filepath.Join(dir, "a.yaml"))
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+
+	warnings := cfg.Lint()
+	want := filepath.Join(dir, "a.yaml") + ":3:5: minor[0]: pattern `^feat:` duplicates minor[0] of " + filepath.Join(dir, "b.yaml")
+	if len(warnings) != 1 || warnings[0].String() != want {
+		t.Errorf("Lint() = %v, want %q", warnings, want)
+	}
+}
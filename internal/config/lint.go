@@ -0,0 +1,233 @@
+package config
+
+import (
+	"fmt"
+	"regexp"
+	"regexp/syntax"
+	"strings"
+	"unicode"
+)
+
+// pattern is a compiled bump pattern with its location
+type pattern struct {
+	section string
+	index   int
+	re      *regexp.Regexp
+}
+
+func (p pattern) keys() fieldPath {
+	return fieldPath{p.section, p.index}
+}
+
+// patterns returns the compiled bump patterns in order of precedence
+func (c *Config) patterns() []pattern {
+	var patterns []pattern
+	for _, section := range []struct {
+		name    string
+		regexes []*regexp.Regexp
+	}{{"major", c.majorRegexes}, {"minor", c.minorRegexes}, {"patch", c.patchRegexes}} {
+		for i, re := range section.regexes {
+			patterns = append(patterns, pattern{section.name, i, re})
+		}
+	}
+	return patterns
+}
+
+// Lint returns warnings about bump patterns that can never match, match
+// every message, or overlap with a pattern of a higher bump, which always
+// takes precedence. The config must be compiled.
+func (c *Config) Lint() []Warning {
+	var warnings []Warning
+	warn := func(keys fieldPath, format string, args ...any) {
+		pos, path := c.source.resolve(keys)
+		warnings = append(warnings, Warning{
+			Pos:     pos,
+			Path:    path.String(),
+			Message: fmt.Sprintf(format, args...),
+		})
+	}
+
+	patterns := c.patterns()
+	for i, p := range patterns {
+		parsed, err := syntax.Parse(p.re.String(), syntax.Perl)
+		if err != nil {
+			continue
+		}
+		parsed = parsed.Simplify()
+
+		if !canMatch(parsed) {
+			warn(p.keys(), "pattern `%s` can never match", p.re)
+			continue
+		}
+		if p.re.MatchString("") {
+			warn(p.keys(), "pattern `%s` matches every commit message", p.re)
+			continue
+		}
+
+		// Compare with the patterns taking precedence
+		for _, q := range patterns[:i] {
+			if q.re.String() == p.re.String() {
+				warn(p.keys(), "pattern `%s` duplicates %s", p.re, c.ref(q.keys(), p.keys()))
+				break
+			}
+			if q.section == p.section {
+				continue
+			}
+			if example, ok := sample(parsed); ok && p.re.MatchString(example) && q.re.MatchString(example) {
+				warn(p.keys(), "pattern `%s` overlaps %s `%s`, which takes precedence for messages like %q", p.re, c.ref(q.keys(), p.keys()), q.re, example)
+				break
+			}
+		}
+	}
+	return warnings
+}
+
+// ref names the setting at keys in a warning about the setting at from,
+// with the file declaring it when the config extends another one
+func (c *Config) ref(keys, from fieldPath) string {
+	src, path := c.source.declaring(keys)
+	fromSrc, _ := c.source.declaring(from)
+	if src != fromSrc && src.String() != "" {
+		return path.String() + " of " + src.String()
+	}
+	return path.String()
+}
+
+// canMatch reports whether a regexp matches at least one string
+func canMatch(re *syntax.Regexp) bool {
+	switch re.Op {
+	case syntax.OpNoMatch:
+		return false
+	case syntax.OpCharClass:
+		return len(re.Rune) > 0
+	case syntax.OpCapture, syntax.OpPlus:
+		return canMatch(re.Sub[0])
+	case syntax.OpRepeat:
+		return re.Min == 0 || canMatch(re.Sub[0])
+	case syntax.OpAlternate:
+		for _, sub := range re.Sub {
+			if canMatch(sub) {
+				return true
+			}
+		}
+		return false
+	case syntax.OpConcat:
+		for i, sub := range re.Sub {
+			if !canMatch(sub) {
+				return false
+			}
+			// Text anchors can only match at the ends of the message
+			if sub.Op == syntax.OpBeginText && minLength(re.Sub[:i]) > 0 {
+				return false
+			}
+			if sub.Op == syntax.OpEndText && minLength(re.Sub[i+1:]) > 0 {
+				return false
+			}
+		}
+		return true
+	default:
+		return true
+	}
+}
+
+// minLength returns the length of the shortest string matched by the
+// concatenation of subs
+func minLength(subs []*syntax.Regexp) int {
+	n := 0
+	for _, sub := range subs {
+		switch sub.Op {
+		case syntax.OpLiteral:
+			n += len(sub.Rune)
+		case syntax.OpCharClass, syntax.OpAnyChar, syntax.OpAnyCharNotNL:
+			n++
+		case syntax.OpCapture, syntax.OpPlus:
+			n += minLength(sub.Sub[:1])
+		case syntax.OpRepeat:
+			n += sub.Min * minLength(sub.Sub[:1])
+		case syntax.OpConcat:
+			n += minLength(sub.Sub)
+		case syntax.OpAlternate:
+			shortest := -1
+			for _, alt := range sub.Sub {
+				if l := minLength([]*syntax.Regexp{alt}); shortest < 0 || l < shortest {
+					shortest = l
+				}
+			}
+			n += max(shortest, 0)
+		}
+	}
+	return n
+}
+
+// sample returns a short string matched by the regexp, if it can build one:
+// the first alternatives, the fewest repetitions and readable characters
+func sample(re *syntax.Regexp) (string, bool) {
+	var b strings.Builder
+	if !writeSample(&b, re) {
+		return "", false
+	}
+	return b.String(), true
+}
+
+func writeSample(b *strings.Builder, re *syntax.Regexp) bool {
+	switch re.Op {
+	case syntax.OpLiteral:
+		b.WriteString(string(re.Rune))
+	case syntax.OpCharClass:
+		r, ok := sampleRune(re.Rune)
+		if !ok {
+			return false
+		}
+		b.WriteRune(r)
+	case syntax.OpAnyChar, syntax.OpAnyCharNotNL:
+		b.WriteByte('x')
+	case syntax.OpCapture, syntax.OpPlus:
+		return writeSample(b, re.Sub[0])
+	case syntax.OpRepeat:
+		for range re.Min {
+			if !writeSample(b, re.Sub[0]) {
+				return false
+			}
+		}
+	case syntax.OpConcat:
+		for _, sub := range re.Sub {
+			if !writeSample(b, sub) {
+				return false
+			}
+		}
+	case syntax.OpAlternate:
+		for _, sub := range re.Sub {
+			if canMatch(sub) {
+				return writeSample(b, sub)
+			}
+		}
+		return false
+	case syntax.OpNoMatch:
+		return false
+	}
+	// Empty matches, anchors and optional parts add nothing
+	return true
+}
+
+// sampleRune returns a readable rune of a character class, given as pairs
+// of inclusive ranges
+func sampleRune(ranges []rune) (rune, bool) {
+	if len(ranges) == 0 {
+		return 0, false
+	}
+	for _, r := range "xa0 " {
+		for i := 0; i+1 < len(ranges); i += 2 {
+			if ranges[i] <= r && r <= ranges[i+1] {
+				return r, true
+			}
+		}
+	}
+	for i := 0; i+1 < len(ranges); i += 2 {
+		for r := ranges[i]; r <= ranges[i+1] && r-ranges[i] < 128; r++ {
+			if unicode.IsPrint(r) {
+				return r, true
+			}
+		}
+	}
+	return ranges[0], true
+}
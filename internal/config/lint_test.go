@@ -0,0 +1,76 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestLint(t *testing.T) {
+	tests := []struct {
+		name  string
+		cfg   Config
+		paths []string
+	}{
+		{name: "default", cfg: *DefaultConfig()},
+		{
+			name:  "never matches",
+			cfg:   Config{Minor: []string{`^feat:`, `feat:^`, `[^\x00-\x{10FFFF}]`, `x$y`}},
+			paths: []string{"minor[1]", "minor[2]", "minor[3]"},
+		},
+		{
+			name:  "matches everything",
+			cfg:   Config{Patch: []string{`.*`, `^`, `fix|`}},
+			paths: []string{"patch[0]", "patch[1]", "patch[2]"},
+		},
+		{
+			name:  "duplicate",
+			cfg:   Config{Minor: []string{`^feat:`, `^feat:`}, Patch: []string{`^feat:`}},
+			paths: []string{"minor[1]", "patch[0]"},
+		},
+		{
+			name:  "overlap",
+			cfg:   Config{Major: []string{`^feat.*:`}, Minor: []string{`^feat(\(.+\))?:`, `^add:`}},
+			paths: []string{"minor[0]"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.cfg.Compile(); err != nil {
+				t.Fatalf("Compile() error = %v", err)
+			}
+			warnings := tt.cfg.Lint()
+			var paths []string
+			for _, w := range warnings {
+				paths = append(paths, w.Path)
+			}
+			if len(paths) != len(tt.paths) {
+				t.Fatalf("Lint() = %v, want warnings for %v", warnings, tt.paths)
+			}
+			for i := range paths {
+				if paths[i] != tt.paths[i] {
+					t.Errorf("Lint() = %v, want warnings for %v", warnings, tt.paths)
+				}
+			}
+		})
+	}
+}
+
+func TestLint_Position(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "cfg.yaml")
+	content := "major:\n  - '^feat!:'\nminor:\n  - '^feat!:'\n"
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatalf("Failed to write config: %v", err)
+	}
+	cfg, err := Load(path)
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+
+	warnings := cfg.Lint()
+	want := path + ":4:5: minor[0]: pattern `^feat!:` duplicates major[0]"
+	if len(warnings) != 1 || warnings[0].String() != want {
+		t.Errorf("Lint() = %v, want %q", warnings, want)
+	}
+}
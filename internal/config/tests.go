@@ -0,0 +1,89 @@
+package config
+
+import (
+	"fmt"
+
+	"github.com/TheScenery/sem-version/pkg/version"
+)
+
+// Test is a sample commit message with the bump the patterns must give it
+type Test struct {
+	// Message is the full commit message
+	Message string `yaml:"message"`
+	// Bump is the expected bump: major, minor, patch or none
+	Bump string `yaml:"bump"`
+}
+
+// TestResult is the outcome of a config test
+type TestResult struct {
+	Pos  Position
+	Path string
+	Test Test
+	// Got is the bump the patterns give to the message
+	Got version.BumpType
+	// Pattern is the first pattern matching the message, e.g. "minor[0]",
+	// empty when none matches
+	Pattern string
+	// Regexp is the matching pattern itself
+	Regexp string
+}
+
+// Passed reports whether the message got the expected bump
+func (r TestResult) Passed() bool {
+	return r.Got.String() == r.Test.Bump
+}
+
+func (r TestResult) String() string {
+	msg := fmt.Sprintf("%s: %q bumps %s", r.Path, r.Test.Message, r.Got)
+	if r.Pattern != "" {
+		msg += fmt.Sprintf(" (%s `%s`)", r.Pattern, r.Regexp)
+	}
+	if !r.Passed() {
+		msg += ", want " + r.Test.Bump
+	}
+	if pos := r.Pos.String(); pos != "" {
+		msg = pos + ": " + msg
+	}
+	return msg
+}
+
+// validateTests checks that each test has a message and a valid bump
+func (c *Config) validateTests() error {
+	for i, t := range c.Tests {
+		if t.Message == "" {
+			return errorAt(fieldPath{"tests", i}, "message is required")
+		}
+		if _, err := version.ParseBumpType(t.Bump); err != nil {
+			return errorAt(fieldPath{"tests", i, "bump"}, "%w", err)
+		}
+	}
+	return nil
+}
+
+// RunTests classifies the message of each test with the compiled patterns
+func (c *Config) RunTests() []TestResult {
+	results := make([]TestResult, 0, len(c.Tests))
+	for i, t := range c.Tests {
+		keys := fieldPath{"tests", i}
+		pos, path := c.source.resolve(keys)
+		r := TestResult{Pos: pos, Path: path.String(), Test: t}
+		if p, ok := c.firstMatch(t.Message); ok {
+			// Sections are named after their bump
+			r.Got, _ = version.ParseBumpType(p.section)
+			r.Pattern, r.Regexp = p.keys().String(), p.re.String()
+		}
+		results = append(results, r)
+	}
+	return results
+}
+
+// firstMatch returns the pattern deciding the bump of a message, which is
+// the one Classify applies
+func (c *Config) firstMatch(message string) (pattern, bool) {
+	for _, p := range c.patterns() {
+		if p.re.MatchString(message) {
+			return p, true
+		}
+	}
+	return pattern{}, false
+}
@@ -0,0 +1,75 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/TheScenery/sem-version/pkg/version"
+)
+
+func TestRunTests(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "cfg.yaml")
+	content := `minor:
+  - '^feat:'
+patch:
+  - '^fix:'
+tests:
+  - message: "feat: add login"
+    bump: minor
+  - message: "fix: typo\n\nBREAKING CHANGE: renamed flag"
+    bump: major
+  - message: "docs: readme"
+    bump: none
+`
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatalf("Failed to write config: %v", err)
+	}
+	cfg, err := Load(path)
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+
+	results := cfg.RunTests()
+	want := []struct {
+		passed  bool
+		got     version.BumpType
+		pattern string
+	}{
+		{true, version.BumpMinorType, "minor[0]"},
+		{false, version.BumpPatchType, "patch[0]"},
+		{true, version.BumpNone, ""},
+	}
+	if len(results) != len(want) {
+		t.Fatalf("RunTests() returned %d results, want %d", len(results), len(want))
+	}
+	for i, w := range want {
+		r := results[i]
+		if r.Passed() != w.passed || r.Got != w.got || r.Pattern != w.pattern {
+			t.Errorf("RunTests()[%d] = %v", i, r)
+		}
+	}
+
+	wantFailure := path + `:8:5: tests[1]: "fix: typo\n\nBREAKING CHANGE: renamed flag" bumps patch (patch[0] ` + "`^fix:`" + `), want major`
+	if got := results[1].String(); got != wantFailure {
+		t.Errorf("String() = %q, want %q", got, wantFailure)
+	}
+}
+
+func TestLoad_InvalidTests(t *testing.T) {
+	for _, content := range []string{
+		"tests:\n  - bump: minor\n",
+		"tests:\n  - message: 'feat: x'\n    bump: feature\n",
+		"tests:\n  - message: 'feat: x'\n    want: minor\n",
+	} {
+		t.Run(content, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), ".sem-version.yaml")
+			if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+				t.Fatalf("Failed to write config: %v", err)
+			}
+			if _, err := Load(path); err == nil {
+				t.Error("Load() expected error")
+			}
+		})
+	}
+}
@@ -0,0 +1,375 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Position locates a value in a config file
+type Position struct {
+	File   string
+	Line   int
+	Column int
+}
+
+// String returns "file:line:column", omitting the unknown parts
+func (p Position) String() string {
+	s := p.File
+	if p.Line > 0 {
+		s += ":" + strconv.Itoa(p.Line)
+		if p.Column > 0 {
+			s += ":" + strconv.Itoa(p.Column)
+		}
+	}
+	return strings.TrimPrefix(s, ":")
+}
+
+// Error is an invalid config setting
+type Error struct {
+	// Pos is the position of the setting, when loaded from a file
+	Pos Position
+	// Path names the setting, e.g. "minor[1]" or "packages[0].path"
+	Path string
+	Err  error
+
+	keys fieldPath
+}
+
+func (e *Error) Error() string {
+	msg := e.Err.Error()
+	if e.Path != "" {
+		msg = e.Path + ": " + msg
+	}
+	if pos := e.Pos.String(); pos != "" {
+		msg = pos + ": " + msg
+	}
+	return msg
+}
+
+func (e *Error) Unwrap() error {
+	return e.Err
+}
+
+// Warning is a valid but suspicious config setting
+type Warning struct {
+	Pos     Position
+	Path    string
+	Message string
+}
+
+func (w Warning) String() string {
+	msg := w.Path + ": " + w.Message
+	if pos := w.Pos.String(); pos != "" {
+		msg = pos + ": " + msg
+	}
+	return msg
+}
+
+// fieldPath is the sequence of keys and indexes leading to a setting
+type fieldPath []any
+
+func (p fieldPath) String() string {
+	var b strings.Builder
+	for _, k := range p {
+		switch k := k.(type) {
+		case int:
+			fmt.Fprintf(&b, "[%d]", k)
+		default:
+			if b.Len() > 0 {
+				b.WriteString(".")
+			}
+			fmt.Fprint(&b, k)
+		}
+	}
+	return b.String()
+}
+
+// errorAt returns an error of the setting at keys
+func errorAt(keys fieldPath, format string, args ...any) *Error {
+	return &Error{Path: keys.String(), Err: fmt.Errorf(format, args...), keys: keys}
+}
+
+// source is the parsed config file, used to locate settings
+type source struct {
+	file string
+	root *yaml.Node
+
+	// name describes a source without file, such as a preset
+	name string
+	// inherited locates the settings merged from the configs of extends,
+	// see merger
+	inherited map[string]origin
+	elements  map[string][]origin
+}
+
+// origin is the source declaring a merged setting, and the index of the
+// element in its list
+type origin struct {
+	src   *source
+	index int
+}
+
+// String returns the file or the name of the source
+func (s *source) String() string {
+	if s == nil {
+		return ""
+	}
+	if s.file != "" {
+		return s.file
+	}
+	return s.name
+}
+
+// declaring returns the source declaring the setting at keys, and its path
+// in that source
+func (s *source) declaring(keys fieldPath) (*source, fieldPath) {
+	if s == nil {
+		return nil, keys
+	}
+
+	if len(keys) >= 2 {
+		if index, ok := keys[1].(int); ok {
+			if elems, ok := s.elements[keys[0].(string)]; ok && index < len(elems) {
+				o := elems[index]
+				return o.src.declaring(append(fieldPath{keys[0], o.index}, keys[2:]...))
+			}
+		}
+		if o, ok := s.inherited[keys[:2].String()]; ok {
+			return o.src.declaring(keys)
+		}
+	}
+	if len(keys) >= 1 {
+		if o, ok := s.inherited[keys[:1].String()]; ok {
+			return o.src.declaring(keys)
+		}
+	}
+	return s, keys
+}
+
+// resolve returns the position of the setting at keys, or of its closest
+// parent present in the file, and its path in the file declaring it
+func (s *source) resolve(keys fieldPath) (Position, fieldPath) {
+	s, keys = s.declaring(keys)
+	if s == nil || s.root == nil {
+		return Position{}, keys
+	}
+
+	node := s.root
+	if node.Kind == yaml.DocumentNode && len(node.Content) > 0 {
+		node = node.Content[0]
+	}
+	for _, k := range keys {
+		next := child(node, k)
+		if next == nil {
+			break
+		}
+		node = next
+	}
+	return Position{File: s.file, Line: node.Line, Column: node.Column}, keys
+}
+
+// child returns the value of a mapping key or the element of a sequence
+func child(node *yaml.Node, key any) *yaml.Node {
+	if node.Kind == yaml.AliasNode {
+		node = node.Alias
+	}
+	switch key := key.(type) {
+	case int:
+		if node.Kind == yaml.SequenceNode && key < len(node.Content) {
+			return node.Content[key]
+		}
+	case string:
+		if node.Kind == yaml.MappingNode {
+			for i := 0; i+1 < len(node.Content); i += 2 {
+				if node.Content[i].Value == key {
+					return node.Content[i+1]
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// locate fills in the position of a config error, and its path in the file
+// declaring the setting
+func (s *source) locate(err error) error {
+	var e *Error
+	if s != nil && errors.As(err, &e) && e.Pos == (Position{}) && e.keys != nil {
+		var keys fieldPath
+		e.Pos, keys = s.resolve(e.keys)
+		e.Path = keys.String()
+	}
+	return err
+}
+
+// yamlLineRegex matches the line number reported by the yaml package
+var yamlLineRegex = regexp.MustCompile(`^(?:yaml: )?line (\d+): (.*)$`)
+
+// yamlError converts a syntax or type error of the yaml package
+func yamlError(file string, err error) error {
+	msg := err.Error()
+	var typeErr *yaml.TypeError
+	if errors.As(err, &typeErr) && len(typeErr.Errors) > 0 {
+		msg = typeErr.Errors[0]
+	}
+
+	e := &Error{Pos: Position{File: file}, Err: errors.New(strings.TrimPrefix(msg, "yaml: "))}
+	if m := yamlLineRegex.FindStringSubmatch(msg); m != nil {
+		e.Pos.Line, _ = strconv.Atoi(m[1])
+		e.Err = errors.New(m[2])
+	}
+	return e
+}
+
+// checkNode reports the first key of node unknown to type t, and values
+// of the wrong kind, which the yaml package would silently ignore or report
+// without a column
+func checkNode(file string, node *yaml.Node, t reflect.Type, keys fieldPath) error {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	switch node.Kind {
+	case 0:
+		// Empty file
+		return nil
+	case yaml.DocumentNode:
+		if len(node.Content) == 0 {
+			return nil
+		}
+		return checkNode(file, node.Content[0], t, keys)
+	case yaml.AliasNode:
+		return checkNode(file, node.Alias, t, keys)
+	}
+	if node.Kind == yaml.ScalarNode && node.ShortTag() == "!!null" {
+		return nil
+	}
+
+	kindError := func(want string) error {
+		return &Error{
+			Pos:  Position{File: file, Line: node.Line, Column: node.Column},
+			Path: keys.String(),
+			Err:  fmt.Errorf("want %s, got %s", want, describeNode(node)),
+		}
+	}
+
+	switch t.Kind() {
+	case reflect.Struct:
+		if node.Kind != yaml.MappingNode {
+			return kindError("a mapping")
+		}
+		fields := yamlFields(t)
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			key, value := node.Content[i], node.Content[i+1]
+			if key.ShortTag() == "!!merge" {
+				if err := checkNode(file, value, t, keys); err != nil {
+					return err
+				}
+				continue
+			}
+			field, ok := fields[key.Value]
+			if !ok {
+				return &Error{
+					Pos:  Position{File: file, Line: key.Line, Column: key.Column},
+					Path: append(keys[:len(keys):len(keys)], key.Value).String(),
+					Err:  fmt.Errorf("unknown key%s", suggest(key.Value, fields)),
+				}
+			}
+			if err := checkNode(file, value, field.Type, append(keys[:len(keys):len(keys)], key.Value)); err != nil {
+				return err
+			}
+		}
+	case reflect.Map:
+		if node.Kind != yaml.MappingNode {
+			return kindError("a mapping")
+		}
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			if err := checkNode(file, node.Content[i+1], t.Elem(), append(keys[:len(keys):len(keys)], node.Content[i].Value)); err != nil {
+				return err
+			}
+		}
+	case reflect.Slice:
+		if node.Kind != yaml.SequenceNode {
+			return kindError("a list")
+		}
+		for i, elem := range node.Content {
+			if err := checkNode(file, elem, t.Elem(), append(keys[:len(keys):len(keys)], i)); err != nil {
+				return err
+			}
+		}
+	default:
+		if node.Kind != yaml.ScalarNode {
+			return kindError("a single value")
+		}
+	}
+	return nil
+}
+
+// describeNode names the kind of a yaml value for error messages
+func describeNode(node *yaml.Node) string {
+	switch node.Kind {
+	case yaml.MappingNode:
+		return "a mapping"
+	case yaml.SequenceNode:
+		return "a list"
+	default:
+		return fmt.Sprintf("%q", node.Value)
+	}
+}
+
+// yamlFields returns the fields of a struct by yaml key
+func yamlFields(t reflect.Type) map[string]reflect.StructField {
+	fields := make(map[string]reflect.StructField)
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		name, _, _ := strings.Cut(f.Tag.Get("yaml"), ",")
+		if !f.IsExported() || name == "-" {
+			continue
+		}
+		if name == "" {
+			name = strings.ToLower(f.Name)
+		}
+		fields[name] = f
+	}
+	return fields
+}
+
+// suggest returns a hint naming the known key closest to a misspelled one
+func suggest(key string, fields map[string]reflect.StructField) string {
+	best, bestDistance := "", 3
+	for name := range fields {
+		if d := editDistance(key, name); d < bestDistance || (d == bestDistance && name < best) {
+			best, bestDistance = name, d
+		}
+	}
+	if best == "" {
+		return ""
+	}
+	return fmt.Sprintf(" (did you mean %s?)", best)
+}
+
+// editDistance returns the Levenshtein distance between a and b
+func editDistance(a, b string) int {
+	prev := make([]int, len(b)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(a); i++ {
+		cur := make([]int, len(b)+1)
+		cur[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			cur[j] = min(prev[j]+1, cur[j-1]+1, prev[j-1]+cost)
+		}
+		prev = cur
+	}
+	return prev[len(b)]
+}
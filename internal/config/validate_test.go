@@ -0,0 +1,99 @@
+package config
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestLoad_Errors(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		want    string
+	}{
+		{
+			name:    "unknown key",
+			content: "major:\n  - '^break:'\nminro:\n  - '^add:'\n",
+			want:    "cfg.yaml:3:1: minro: unknown key (did you mean minor?)",
+		},
+		{
+			name:    "unknown nested key",
+			content: "packages:\n  - name: api\n    path: api\n    tag-prefix: api/\n",
+			want:    "cfg.yaml:4:5: packages[0].tag-prefix: unknown key (did you mean tag_prefix?)",
+		},
+		{
+			name:    "wrong kind",
+			content: "minor: '^feat:'\n",
+			want:    `cfg.yaml:1:8: minor: want a list, got "^feat:"`,
+		},
+		{
+			name:    "invalid pattern",
+			content: "minor:\n  - '^feat:'\n  - '^add(:'\n",
+			want:    "cfg.yaml:3:5: minor[1]: invalid pattern `^add(:`: missing closing )",
+		},
+		{
+			name:    "invalid value",
+			content: "history: all\nmerge_commits: title\n",
+			want:    `cfg.yaml:2:16: merge_commits: invalid value "title" (want message or pull-request)`,
+		},
+		{
+			name:    "unknown dependency",
+			content: "packages:\n  - name: api\n    path: api\n    depends_on: [core]\n",
+			want:    "cfg.yaml:4:18: packages[0].depends_on[0]: unknown dependency core",
+		},
+		{
+			name:    "missing value",
+			content: "bump_files:\n  - path: package.json\n    format: json\n",
+			want:    "cfg.yaml:2:5: bump_files[0]: key is required for format json",
+		},
+		{
+			name:    "invalid glob",
+			content: "exclude_paths: [docs/, '']\n",
+			want:    "cfg.yaml:1:24: exclude_paths[1]: empty glob",
+		},
+		{
+			name:    "syntax error",
+			content: "major:\n  - '^break:\n",
+			want:    "cfg.yaml:2: found unexpected end of stream",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			path := filepath.Join(dir, "cfg.yaml")
+			if err := os.WriteFile(path, []byte(tt.content), 0644); err != nil {
+				t.Fatalf("Failed to write config: %v", err)
+			}
+
+			_, err := Load(path)
+			var cfgErr *Error
+			if !errors.As(err, &cfgErr) {
+				t.Fatalf("Load() error = %v, want *Error", err)
+			}
+			if got := cfgErr.Error(); got != filepath.Join(dir, tt.want) {
+				t.Errorf("Load() error = %q, want %q", got, filepath.Join(dir, tt.want))
+			}
+		})
+	}
+}
+
+func TestLoad_Empty(t *testing.T) {
+	path := filepath.Join(t.TempDir(), ".sem-version.yaml")
+	if err := os.WriteFile(path, []byte("# no settings\n"), 0644); err != nil {
+		t.Fatalf("Failed to write config: %v", err)
+	}
+	if _, err := Load(path); err != nil {
+		t.Errorf("Load() error = %v", err)
+	}
+}
+
+func TestCompile_Error(t *testing.T) {
+	cfg := &Config{Patch: []string{`^fix:`, `[`}}
+	err := cfg.Compile()
+	if err == nil || err.Error() != "patch[1]: invalid pattern `[`: missing closing ]" {
+		t.Errorf("Compile() error = %v", err)
+	}
+}
@@ -0,0 +1,53 @@
+package generate
+
+import (
+	"bytes"
+	"fmt"
+	"go/format"
+	"go/token"
+	"text/template"
+
+	"github.com/TheScenery/sem-version/pkg/version"
+)
+
+// BuildInfo holds the values written into generated source files
+type BuildInfo struct {
+	Version version.Version
+	Commit  string
+	Dirty   bool
+}
+
+var goTemplate = template.Must(template.New("go").Parse(`// Code generated by sem-version; DO NOT EDIT.
+
+package {{.Package}}
+
+// Build information computed by sem-version
+const (
+	Version    = {{printf "%q" .Info.Version.String}}
+	Major      = {{.Info.Version.Major}}
+	Minor      = {{.Info.Version.Minor}}
+	Patch      = {{.Info.Version.Patch}}
+	Prerelease = {{printf "%q" .Info.Version.Prerelease}}
+	Commit     = {{printf "%q" .Info.Commit}}
+	Dirty      = {{.Info.Dirty}}
+)
+`))
+
+// GoFile returns the formatted source of a Go file declaring the build
+// information as constants in the given package
+func GoFile(pkg string, info BuildInfo) ([]byte, error) {
+	if !token.IsIdentifier(pkg) {
+		return nil, fmt.Errorf("invalid package name: %q", pkg)
+	}
+
+	var buf bytes.Buffer
+	err := goTemplate.Execute(&buf, struct {
+		Package string
+		Info    BuildInfo
+	}{pkg, info})
+	if err != nil {
+		return nil, err
+	}
+
+	return format.Source(buf.Bytes())
+}
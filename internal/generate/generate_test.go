@@ -0,0 +1,44 @@
+package generate
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/TheScenery/sem-version/pkg/version"
+)
+
+func TestGoFile(t *testing.T) {
+	info := BuildInfo{
+		Version: version.Version{Major: 1, Minor: 3, Patch: 0, Prerelease: "rc.1"},
+		Commit:  "1a2b3c4d",
+		Dirty:   true,
+	}
+
+	src, err := GoFile("buildinfo", info)
+	if err != nil {
+		t.Fatalf("GoFile() error = %v", err)
+	}
+
+	want := []string{
+		"// Code generated by sem-version; DO NOT EDIT.",
+		"package buildinfo",
+		`Version    = "v1.3.0-rc.1"`,
+		"Major      = 1",
+		"Minor      = 3",
+		"Patch      = 0",
+		`Prerelease = "rc.1"`,
+		`Commit     = "1a2b3c4d"`,
+		"Dirty      = true",
+	}
+	for _, w := range want {
+		if !strings.Contains(string(src), w) {
+			t.Errorf("GoFile() missing %q in:\n%s", w, src)
+		}
+	}
+}
+
+func TestGoFile_InvalidPackage(t *testing.T) {
+	if _, err := GoFile("build-info", BuildInfo{}); err == nil {
+		t.Error("Expected error for invalid package name")
+	}
+}
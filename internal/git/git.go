@@ -2,96 +2,365 @@ package git
 
 import (
 	"bytes"
+	"context"
 	"errors"
+	"fmt"
+	"io/fs"
+	"os"
 	"os/exec"
+	"path/filepath"
+	"strconv"
 	"strings"
+	"time"
 )
 
+// Errors wrapped by the errors of git commands, to be tested with errors.Is
+var (
+	// ErrGitNotFound reports that the git executable is not in PATH
+	ErrGitNotFound = errors.New("git executable not found")
+	// ErrNotARepository reports that the path is not inside a git repository
+	ErrNotARepository = errors.New("not a git repository")
+	// ErrNoCommits reports a repository whose current branch has no commits
+	ErrNoCommits = errors.New("repository has no commits")
+	// ErrShallow reports that the history needed is missing from a shallow clone
+	ErrShallow = errors.New("shallow repository")
+)
+
+// Error is a failed git command
+type Error struct {
+	// Args are the arguments of the git command
+	Args []string
+	// Stderr is the trimmed error output of the command
+	Stderr string
+	// Err is one of the sentinel errors above, the error of the context
+	// when it is done, or the underlying exec error
+	Err error
+}
+
+func (e *Error) Error() string {
+	msg := e.Stderr
+	if msg == "" {
+		msg = e.Err.Error()
+	}
+	return "git " + e.subcommand() + ": " + msg
+}
+
+// subcommand returns the git subcommand, skipping global options
+func (e *Error) subcommand() string {
+	for i := 0; i < len(e.Args); i++ {
+		switch {
+		case e.Args[i] == "-c":
+			i++
+		case !strings.HasPrefix(e.Args[i], "-"):
+			return e.Args[i]
+		}
+	}
+	return ""
+}
+
+func (e *Error) Unwrap() error {
+	return e.Err
+}
+
+// waitDelay bounds the wait for the pipes of a killed git command, which its
+// children, such as ssh or a credential helper, may keep open
+const waitDelay = 2 * time.Second
+
+// run runs a git command in repoPath and returns its standard output
+// The command is killed when ctx is done, and never prompts for credentials.
+func run(ctx context.Context, repoPath string, args ...string) (string, error) {
+	cmd := exec.CommandContext(ctx, "git", args...)
+	cmd.Dir = repoPath
+	cmd.WaitDelay = waitDelay
+	cmd.Env = append(os.Environ(), "GIT_TERMINAL_PROMPT=0")
+	if _, ok := os.LookupEnv("GIT_SSH_COMMAND"); !ok {
+		// Fail instead of asking for a passphrase or a host key confirmation
+		cmd.Env = append(cmd.Env, "GIT_SSH_COMMAND=ssh -o BatchMode=yes")
+	}
+
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+
+	if err := cmd.Run(); err != nil {
+		if ctx.Err() != nil {
+			return stdout.String(), &Error{Args: args, Stderr: strings.TrimSpace(stderr.String()), Err: ctx.Err()}
+		}
+		return stdout.String(), newError(args, strings.TrimSpace(stderr.String()), err)
+	}
+	return stdout.String(), nil
+}
+
+// newError classifies the failure of a git command
+func newError(args []string, stderr string, err error) *Error {
+	e := &Error{Args: args, Stderr: stderr, Err: err}
+
+	var exitErr *exec.ExitError
+	switch {
+	case errors.Is(err, exec.ErrNotFound):
+		e.Err = ErrGitNotFound
+	case errors.Is(err, fs.ErrNotExist) && !errors.As(err, &exitErr):
+		// The repository directory does not exist
+		e.Err = ErrNotARepository
+		e.Stderr = err.Error()
+	case strings.Contains(stderr, "not a git repository"):
+		e.Err = ErrNotARepository
+	case strings.Contains(stderr, "does not have any commits yet"),
+		strings.Contains(stderr, "ambiguous argument 'HEAD'"):
+		e.Err = ErrNoCommits
+	}
+	return e
+}
+
 // Commit represents a git commit
 type Commit struct {
 	Hash    string
 	Message string
+	// Merge reports whether the commit has more than one parent
+	Merge bool
+	// Files lists the files changed by the commit, relative to the
+	// repository root, when requested with LogOptions.Files
+	Files []string
+}
+
+// LogOptions selects the commits returned by GetCommits
+type LogOptions struct {
+	// Since excludes the commits reachable from this tag, empty for all commits
+	Since string
+	// Path keeps only the commits touching this path, empty for all commits
+	Path string
+	// FirstParent follows only the first parent of merge commits
+	FirstParent bool
+	// Files lists the changed files of each commit, compared to the first
+	// parent for merge commits
+	Files bool
 }
 
 // GetLatestTag returns the latest semver tag in the repository
-func GetLatestTag(repoPath string) (string, error) {
-	cmd := exec.Command("git", "describe", "--tags", "--abbrev=0", "--match", "v*")
-	cmd.Dir = repoPath
+func GetLatestTag(ctx context.Context, repoPath string) (string, error) {
+	return GetLatestTagWithPrefix(ctx, repoPath, "")
+}
 
-	var stdout, stderr bytes.Buffer
-	cmd.Stdout = &stdout
-	cmd.Stderr = &stderr
+// GetLatestTagWithPrefix returns the latest semver tag whose name starts with
+// prefix followed by "v" (e.g. "api/v1.2.0" for prefix "api/")
+func GetLatestTagWithPrefix(ctx context.Context, repoPath, prefix string) (string, error) {
+	return describeTag(ctx, repoPath, "--match", prefix+"v*")
+}
+
+// GetLatestReleaseTagWithPrefix is like GetLatestTagWithPrefix but ignores
+// prerelease tags such as "v1.3.0-next.1"
+func GetLatestReleaseTagWithPrefix(ctx context.Context, repoPath, prefix string) (string, error) {
+	return describeTag(ctx, repoPath, "--match", prefix+"v*", "--exclude", prefix+"v*-*")
+}
+
+// GetLatestTagMatching returns the latest tag reachable from HEAD matching
+// the glob pattern, e.g. "v*-next.*"
+func GetLatestTagMatching(ctx context.Context, repoPath, pattern string) (string, error) {
+	return describeTag(ctx, repoPath, "--match", pattern)
+}
 
-	err := cmd.Run()
+// describeTag returns the latest tag reachable from HEAD matching the filter args
+func describeTag(ctx context.Context, repoPath string, filter ...string) (string, error) {
+	args := append([]string{"describe", "--tags", "--abbrev=0"}, filter...)
+	out, err := run(ctx, repoPath, args...)
 	if err != nil {
 		// No tags found is not an error for our use case
-		if strings.Contains(stderr.String(), "No names found") ||
-			strings.Contains(stderr.String(), "No tags can describe") ||
-			strings.Contains(stderr.String(), "fatal") {
+		var gitErr *Error
+		if errors.As(err, &gitErr) &&
+			(strings.Contains(gitErr.Stderr, "No names found") ||
+				strings.Contains(gitErr.Stderr, "No tags can describe")) {
 			return "", nil
 		}
 		return "", err
 	}
 
-	return strings.TrimSpace(stdout.String()), nil
+	return strings.TrimSpace(out), nil
 }
 
 // GetCommitsSince returns all commits since the given tag
 // If tag is empty, returns all commits
-func GetCommitsSince(repoPath, tag string) ([]Commit, error) {
-	var args []string
-	if tag == "" {
-		args = []string{"log", "--pretty=format:%H|%s", "--reverse"}
-	} else {
-		args = []string{"log", "--pretty=format:%H|%s", "--reverse", tag + "..HEAD"}
-	}
+func GetCommitsSince(ctx context.Context, repoPath, tag string) ([]Commit, error) {
+	return GetCommitsSinceInPath(ctx, repoPath, tag, "")
+}
 
-	cmd := exec.Command("git", args...)
-	cmd.Dir = repoPath
+// GetCommitsSinceInPath returns all commits since the given tag that touch path
+// If path is empty, commits are not filtered by path
+func GetCommitsSinceInPath(ctx context.Context, repoPath, tag, path string) ([]Commit, error) {
+	return GetCommits(ctx, repoPath, LogOptions{Since: tag, Path: path})
+}
 
-	var stdout, stderr bytes.Buffer
-	cmd.Stdout = &stdout
-	cmd.Stderr = &stderr
+// GetCommits returns the commits selected by opts, oldest first: parents
+// always come before their children, even when the commit dates disagree
+func GetCommits(ctx context.Context, repoPath string, opts LogOptions) ([]Commit, error) {
+	// Each commit starts with a record separator, followed by its changed
+	// files on separate lines when requested
+	args := []string{"-c", "core.quotePath=false", "log", "--pretty=format:%x1e%H|%P|%s", "--reverse", "--topo-order"}
+	if opts.FirstParent {
+		args = append(args, "--first-parent")
+	}
+	if opts.Files {
+		args = append(args, "--name-only", "--diff-merges=first-parent")
+	}
+	if opts.Since != "" {
+		args = append(args, opts.Since+"..HEAD")
+	}
+	if opts.Path != "" {
+		args = append(args, "--", opts.Path)
+	}
 
-	err := cmd.Run()
+	out, err := run(ctx, repoPath, args...)
 	if err != nil {
-		return nil, errors.New(stderr.String())
+		return nil, err
 	}
 
-	output := strings.TrimSpace(stdout.String())
-	if output == "" {
-		return nil, nil
-	}
+	records := strings.Split(out, "\x1e")
+	commits := make([]Commit, 0, len(records))
 
-	lines := strings.Split(output, "\n")
-	commits := make([]Commit, 0, len(lines))
+	for _, record := range records {
+		lines := strings.Split(strings.TrimSpace(record), "\n")
+		parts := strings.SplitN(lines[0], "|", 3)
+		if len(parts) != 3 {
+			continue
+		}
 
-	for _, line := range lines {
-		parts := strings.SplitN(line, "|", 2)
-		if len(parts) == 2 {
-			commits = append(commits, Commit{
-				Hash:    parts[0],
-				Message: parts[1],
-			})
+		c := Commit{
+			Hash:    parts[0],
+			Message: parts[2],
+			Merge:   len(strings.Fields(parts[1])) > 1,
 		}
+		for _, file := range lines[1:] {
+			if file != "" {
+				c.Files = append(c.Files, file)
+			}
+		}
+		commits = append(commits, c)
 	}
 
+	if len(commits) == 0 {
+		return nil, nil
+	}
 	return commits, nil
 }
 
+// CountCommitsSince returns the number of commits reachable from HEAD but not
+// from tag, restricted to those changing path when not empty
+func CountCommitsSince(ctx context.Context, repoPath, tag, path string) (int, error) {
+	args := []string{"rev-list", "--count", tag + "..HEAD"}
+	if path != "" {
+		args = append(args, "--", path)
+	}
+	out, err := run(ctx, repoPath, args...)
+	if err != nil {
+		return 0, err
+	}
+	return strconv.Atoi(strings.TrimSpace(out))
+}
+
 // GetFullCommitMessage returns the full commit message including body
-func GetFullCommitMessage(repoPath, hash string) (string, error) {
-	cmd := exec.Command("git", "log", "-1", "--pretty=format:%B", hash)
-	cmd.Dir = repoPath
+func GetFullCommitMessage(ctx context.Context, repoPath, hash string) (string, error) {
+	return run(ctx, repoPath, "log", "-1", "--pretty=format:%B", hash)
+}
 
-	var stdout, stderr bytes.Buffer
-	cmd.Stdout = &stdout
-	cmd.Stderr = &stderr
+// GetHeadCommit returns the full hash of the HEAD commit
+func GetHeadCommit(ctx context.Context, repoPath string) (string, error) {
+	out, err := run(ctx, repoPath, "rev-parse", "HEAD")
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(out), nil
+}
 
-	err := cmd.Run()
+// IsDirty returns true if the worktree has uncommitted changes
+func IsDirty(ctx context.Context, repoPath string) (bool, error) {
+	out, err := run(ctx, repoPath, "status", "--porcelain")
 	if err != nil {
-		return "", errors.New(stderr.String())
+		return false, err
 	}
+	return strings.TrimSpace(out) != "", nil
+}
 
-	return stdout.String(), nil
+// CreateTag creates an annotated tag on HEAD
+func CreateTag(ctx context.Context, repoPath, name, message string) error {
+	_, err := run(ctx, repoPath, "tag", "-a", name, "-m", message)
+	return err
+}
+
+// GetCurrentBranch returns the name of the checked out branch
+// Returns an empty string when HEAD is detached
+func GetCurrentBranch(ctx context.Context, repoPath string) (string, error) {
+	out, err := run(ctx, repoPath, "symbolic-ref", "--quiet", "--short", "HEAD")
+	if err != nil {
+		// symbolic-ref exits with 1 and no output when HEAD is detached
+		var exitErr *exec.ExitError
+		if errors.As(err, &exitErr) && exitErr.ExitCode() == 1 {
+			return "", nil
+		}
+		return "", err
+	}
+	return strings.TrimSpace(out), nil
+}
+
+// ListTags returns the tags matching the glob pattern
+func ListTags(ctx context.Context, repoPath, pattern string) ([]string, error) {
+	out, err := run(ctx, repoPath, "tag", "--list", pattern)
+	if err != nil {
+		return nil, err
+	}
+
+	out = strings.TrimSpace(out)
+	if out == "" {
+		return nil, nil
+	}
+	return strings.Split(out, "\n"), nil
+}
+
+// ListTagsPointingAt returns the tags matching the glob pattern that point
+// at the revision
+func ListTagsPointingAt(ctx context.Context, repoPath, rev, pattern string) ([]string, error) {
+	out, err := run(ctx, repoPath, "tag", "--list", pattern, "--points-at", rev)
+	if err != nil {
+		return nil, err
+	}
+
+	out = strings.TrimSpace(out)
+	if out == "" {
+		return nil, nil
+	}
+	return strings.Split(out, "\n"), nil
+}
+
+// GetTopLevel returns the absolute path of the root of the working tree
+// containing repoPath
+func GetTopLevel(ctx context.Context, repoPath string) (string, error) {
+	out, err := run(ctx, repoPath, "rev-parse", "--show-toplevel")
+	if err != nil {
+		return "", err
+	}
+	return filepath.FromSlash(strings.TrimSpace(out)), nil
+}
+
+// GetCommonDir returns the absolute path of the .git directory, shared by
+// all worktrees of the repository
+func GetCommonDir(ctx context.Context, repoPath string) (string, error) {
+	out, err := run(ctx, repoPath, "rev-parse", "--path-format=absolute", "--git-common-dir")
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(out), nil
+}
+
+// IsShallow returns true if the repository is a shallow clone
+func IsShallow(ctx context.Context, repoPath string) (bool, error) {
+	out, err := run(ctx, repoPath, "rev-parse", "--is-shallow-repository")
+	if err != nil {
+		return false, err
+	}
+	return strings.TrimSpace(out) == "true", nil
+}
+
+// Deepen fetches n more commits of history, and the tags pointing into it,
+// from the default remote of a shallow clone
+func Deepen(ctx context.Context, repoPath string, n int) error {
+	_, err := run(ctx, repoPath, "fetch", "--quiet", fmt.Sprintf("--deepen=%d", n))
+	return err
 }
@@ -0,0 +1,272 @@
+package git
+
+import (
+	"context"
+	"errors"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+	"time"
+)
+
+// newRepo creates an empty git repository
+func newRepo(t *testing.T) string {
+	t.Helper()
+	dir := t.TempDir()
+	gitCmd(t, dir, "init", "-q")
+	gitCmd(t, dir, "config", "user.email", "test@example.com")
+	gitCmd(t, dir, "config", "user.name", "test")
+	gitCmd(t, dir, "config", "commit.gpgsign", "false")
+	return dir
+}
+
+func gitCmd(t *testing.T, dir string, args ...string) {
+	t.Helper()
+	cmd := exec.Command("git", args...)
+	cmd.Dir = dir
+	if out, err := cmd.CombinedOutput(); err != nil {
+		t.Fatalf("git %v: %v\n%s", args, err, out)
+	}
+}
+
+func TestErrors(t *testing.T) {
+	empty := newRepo(t)
+
+	tests := []struct {
+		name string
+		path string
+		run  func(string) error
+		want error
+	}{
+		{
+			name: "not a repository",
+			path: t.TempDir(),
+			run:  func(p string) error { _, err := GetLatestTag(context.Background(), p); return err },
+			want: ErrNotARepository,
+		},
+		{
+			name: "missing directory",
+			path: filepath.Join(t.TempDir(), "missing"),
+			run:  func(p string) error { _, err := GetLatestTag(context.Background(), p); return err },
+			want: ErrNotARepository,
+		},
+		{
+			name: "no commits",
+			path: empty,
+			run:  func(p string) error { _, err := GetCommitsSince(context.Background(), p, ""); return err },
+			want: ErrNoCommits,
+		},
+		{
+			name: "no HEAD",
+			path: empty,
+			run:  func(p string) error { _, err := GetHeadCommit(context.Background(), p); return err },
+			want: ErrNoCommits,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.run(tt.path)
+			if !errors.Is(err, tt.want) {
+				t.Fatalf("error = %v, want %v", err, tt.want)
+			}
+			var gitErr *Error
+			if !errors.As(err, &gitErr) {
+				t.Errorf("error = %T, want *Error", err)
+			}
+		})
+	}
+}
+
+func TestErrGitNotFound(t *testing.T) {
+	dir := newRepo(t)
+	t.Setenv("PATH", "")
+
+	if _, err := GetLatestTag(context.Background(), dir); !errors.Is(err, ErrGitNotFound) {
+		t.Errorf("GetLatestTag() error = %v, want ErrGitNotFound", err)
+	}
+}
+
+func TestGetLatestTag_NoTags(t *testing.T) {
+	dir := newRepo(t)
+	gitCmd(t, dir, "commit", "-q", "--allow-empty", "-m", "feat: initial")
+
+	tag, err := GetLatestTag(context.Background(), dir)
+	if err != nil || tag != "" {
+		t.Errorf("GetLatestTag() = %q, %v, want no tag", tag, err)
+	}
+}
+
+func TestGetCommits(t *testing.T) {
+	dir := newRepo(t)
+	gitCmd(t, dir, "commit", "-q", "--allow-empty", "-m", "feat: initial")
+	gitCmd(t, dir, "checkout", "-q", "-b", "topic")
+	if err := os.WriteFile(filepath.Join(dir, "a.txt"), []byte("a"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	gitCmd(t, dir, "add", "a.txt")
+	gitCmd(t, dir, "commit", "-q", "-m", "fix: a | b")
+	gitCmd(t, dir, "checkout", "-q", "-")
+	gitCmd(t, dir, "merge", "-q", "--no-ff", "topic", "-m", "Merge topic")
+
+	commits, err := GetCommits(context.Background(), dir, LogOptions{Files: true})
+	if err != nil {
+		t.Fatalf("GetCommits() error = %v", err)
+	}
+	if len(commits) != 3 {
+		t.Fatalf("GetCommits() = %+v, want 3 commits", commits)
+	}
+	if c := commits[1]; c.Message != "fix: a | b" || c.Merge || len(c.Files) != 1 || c.Files[0] != "a.txt" {
+		t.Errorf("commits[1] = %+v", c)
+	}
+	if c := commits[2]; !c.Merge || len(c.Files) != 1 {
+		t.Errorf("commits[2] = %+v, want merge changing a.txt", c)
+	}
+
+	firstParent, err := GetCommits(context.Background(), dir, LogOptions{FirstParent: true})
+	if err != nil {
+		t.Fatalf("GetCommits() error = %v", err)
+	}
+	if len(firstParent) != 2 || firstParent[1].Files != nil {
+		t.Errorf("GetCommits(FirstParent) = %+v, want 2 commits without files", firstParent)
+	}
+}
+
+func TestGetCommits_ClockSkew(t *testing.T) {
+	dir := newRepo(t)
+	commit := func(message, date string) {
+		t.Helper()
+		t.Setenv("GIT_COMMITTER_DATE", date)
+		t.Setenv("GIT_AUTHOR_DATE", date)
+		gitCmd(t, dir, "commit", "-q", "--allow-empty", "-m", message)
+	}
+
+	// The first commit is dated after its children, as made on a machine
+	// with a clock ahead
+	commit("feat: base", "2024-01-10T00:00:00Z")
+	gitCmd(t, dir, "checkout", "-q", "-b", "topic")
+	commit("fix: topic", "2024-01-03T00:00:00Z")
+	gitCmd(t, dir, "checkout", "-q", "-")
+	commit("fix: one", "2024-01-01T00:00:00Z")
+	commit("fix: two", "2024-01-02T00:00:00Z")
+	t.Setenv("GIT_COMMITTER_DATE", "2024-01-11T00:00:00Z")
+	gitCmd(t, dir, "merge", "-q", "--no-ff", "topic", "-m", "Merge topic")
+
+	commits, err := GetCommits(context.Background(), dir, LogOptions{})
+	if err != nil {
+		t.Fatalf("GetCommits() error = %v", err)
+	}
+	index := make(map[string]int)
+	for i, c := range commits {
+		index[c.Message] = i
+	}
+	for _, edge := range [][2]string{
+		{"feat: base", "fix: topic"},
+		{"feat: base", "fix: one"},
+		{"fix: one", "fix: two"},
+		{"fix: two", "Merge topic"},
+		{"fix: topic", "Merge topic"},
+	} {
+		if index[edge[0]] >= index[edge[1]] {
+			t.Errorf("GetCommits() = %+v, want %q before %q", commits, edge[0], edge[1])
+		}
+	}
+}
+
+func TestGetTopLevel(t *testing.T) {
+	dir := newRepo(t)
+	sub := filepath.Join(dir, "internal", "buildinfo")
+	if err := os.MkdirAll(sub, 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := GetTopLevel(context.Background(), sub)
+	if err != nil {
+		t.Fatalf("GetTopLevel() error = %v", err)
+	}
+	want, err := filepath.EvalSymlinks(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, _ = filepath.EvalSymlinks(got); got != want {
+		t.Errorf("GetTopLevel() = %q, want %q", got, want)
+	}
+}
+
+func TestRun_Cancelled(t *testing.T) {
+	dir := newRepo(t)
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	if _, err := GetLatestTag(ctx, dir); !errors.Is(err, context.Canceled) {
+		t.Errorf("GetLatestTag() error = %v, want context.Canceled", err)
+	}
+}
+
+// fakeGit puts a shell script named git first in PATH
+func fakeGit(t *testing.T, script string) {
+	t.Helper()
+	if runtime.GOOS == "windows" {
+		t.Skip("needs a shell script as git")
+	}
+	bin := t.TempDir()
+	if err := os.WriteFile(filepath.Join(bin, "git"), []byte("#!/bin/sh\n"+script), 0755); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("PATH", bin+string(os.PathListSeparator)+os.Getenv("PATH"))
+}
+
+func TestRun_Timeout(t *testing.T) {
+	// A child of git, like ssh waiting for the network, keeps the output
+	// open after git is killed
+	fakeGit(t, "sleep 10 &\nwait\n")
+
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+
+	start := time.Now()
+	_, err := GetLatestTag(ctx, t.TempDir())
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("GetLatestTag() error = %v, want context.DeadlineExceeded", err)
+	}
+	if elapsed := time.Since(start); elapsed > waitDelay+time.Second {
+		t.Errorf("GetLatestTag() returned after %s, want at most %s", elapsed, waitDelay+time.Second)
+	}
+}
+
+func TestRun_SSHCommand(t *testing.T) {
+	tests := []struct {
+		name string
+		env  string
+		set  bool
+		want string
+	}{
+		{name: "default", want: "ssh -o BatchMode=yes"},
+		{name: "user command", env: "ssh -i key", set: true, want: "ssh -i key"},
+		{name: "empty", env: "", set: true, want: ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fakeGit(t, "printf '%s' \"$GIT_SSH_COMMAND\"\n")
+			if tt.set {
+				t.Setenv("GIT_SSH_COMMAND", tt.env)
+			} else {
+				// Restored after the test
+				t.Setenv("GIT_SSH_COMMAND", "")
+				os.Unsetenv("GIT_SSH_COMMAND")
+			}
+
+			out, err := run(context.Background(), t.TempDir(), "version")
+			if err != nil {
+				t.Fatalf("run() error = %v", err)
+			}
+			if got := strings.TrimSpace(out); got != tt.want {
+				t.Errorf("GIT_SSH_COMMAND = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
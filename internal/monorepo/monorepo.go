@@ -0,0 +1,291 @@
+package monorepo
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/TheScenery/sem-version/internal/config"
+	"github.com/TheScenery/sem-version/pkg/version"
+)
+
+// Graph represents the dependency graph between monorepo packages
+type Graph struct {
+	deps  map[string][]string
+	order []string
+}
+
+// Propagation describes the final bump of a package after dependency propagation
+type Propagation struct {
+	Bump version.BumpType
+	// Causes lists the dependencies whose release forced the bump
+	// It is empty when the package was bumped by its own commits
+	Causes []string
+}
+
+// NewGraph builds the dependency graph from declared dependencies and from
+// go.mod files found in the package directories
+func NewGraph(root string, packages []config.Package) (*Graph, error) {
+	g := &Graph{deps: make(map[string][]string, len(packages))}
+
+	for _, p := range packages {
+		g.deps[p.Name] = nil
+		for _, dep := range p.DependsOn {
+			g.addDependency(p.Name, dep)
+		}
+	}
+
+	if err := g.discoverGoModules(root, packages); err != nil {
+		return nil, err
+	}
+
+	order, err := g.sort(packages)
+	if err != nil {
+		return nil, err
+	}
+	g.order = order
+
+	return g, nil
+}
+
+// Order returns package names with dependencies before their dependents
+func (g *Graph) Order() []string {
+	return g.order
+}
+
+// DependsOn returns the direct dependencies of the named package
+func (g *Graph) DependsOn(name string) []string {
+	return g.deps[name]
+}
+
+// Propagate applies dependency propagation to the bumps computed from commits:
+// a package depending on a released package gets at least a patch bump
+func (g *Graph) Propagate(bumps map[string]version.BumpType) map[string]Propagation {
+	result := make(map[string]Propagation, len(g.order))
+
+	for _, name := range g.order {
+		prop := Propagation{Bump: bumps[name]}
+
+		if prop.Bump == version.BumpNone {
+			for _, dep := range g.deps[name] {
+				if result[dep].Bump != version.BumpNone {
+					prop.Causes = append(prop.Causes, dep)
+				}
+			}
+			if len(prop.Causes) > 0 {
+				prop.Bump = version.BumpPatchType
+			}
+		}
+
+		result[name] = prop
+	}
+
+	return result
+}
+
+func (g *Graph) addDependency(name, dep string) {
+	if name == dep {
+		return
+	}
+	for _, d := range g.deps[name] {
+		if d == dep {
+			return
+		}
+	}
+	g.deps[name] = append(g.deps[name], dep)
+}
+
+// discoverGoModules adds dependencies between packages that are Go modules
+// requiring or replacing each other
+func (g *Graph) discoverGoModules(root string, packages []config.Package) error {
+	modules := make(map[string]goModule)
+	byModulePath := make(map[string]string)
+	byDir := make(map[string]string)
+
+	for _, p := range packages {
+		dir := filepath.Clean(filepath.Join(root, p.Path))
+		byDir[dir] = p.Name
+
+		mod, err := readGoMod(filepath.Join(dir, "go.mod"))
+		if os.IsNotExist(err) {
+			continue
+		}
+		if err != nil {
+			return fmt.Errorf("package %s: %w", p.Name, err)
+		}
+		modules[p.Name] = mod
+		byModulePath[mod.Path] = p.Name
+	}
+
+	for _, p := range packages {
+		mod, ok := modules[p.Name]
+		if !ok {
+			continue
+		}
+
+		for _, req := range mod.Requires {
+			if dep, ok := byModulePath[req]; ok {
+				g.addDependency(p.Name, dep)
+			}
+		}
+
+		dir := filepath.Join(root, p.Path)
+		for _, target := range mod.LocalReplaces {
+			if dep, ok := byDir[filepath.Clean(filepath.Join(dir, target))]; ok {
+				g.addDependency(p.Name, dep)
+			}
+		}
+	}
+
+	return nil
+}
+
+// sort orders packages topologically, keeping declaration order where possible
+func (g *Graph) sort(packages []config.Package) ([]string, error) {
+	const (
+		unvisited = iota
+		visiting
+		done
+	)
+
+	state := make(map[string]int, len(packages))
+	order := make([]string, 0, len(packages))
+
+	var visit func(name string, path []string) error
+	visit = func(name string, path []string) error {
+		switch state[name] {
+		case done:
+			return nil
+		case visiting:
+			return fmt.Errorf("dependency cycle: %s", strings.Join(append(path, name), " -> "))
+		}
+
+		state[name] = visiting
+		deps := append([]string(nil), g.deps[name]...)
+		sort.Strings(deps)
+		for _, dep := range deps {
+			if err := visit(dep, append(path, name)); err != nil {
+				return err
+			}
+		}
+		state[name] = done
+		order = append(order, name)
+		return nil
+	}
+
+	for _, p := range packages {
+		if err := visit(p.Name, nil); err != nil {
+			return nil, err
+		}
+	}
+
+	return order, nil
+}
+
+// goModule holds the parts of a go.mod file relevant for dependency discovery
+type goModule struct {
+	Path          string
+	Requires      []string
+	LocalReplaces []string
+}
+
+// readGoMod extracts the module path, required modules and local replace
+// targets from a go.mod file
+func readGoMod(path string) (goModule, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return goModule{}, err
+	}
+	defer f.Close()
+
+	var mod goModule
+	block := ""
+
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		line := scanner.Text()
+		if i := strings.Index(line, "//"); i >= 0 {
+			line = line[:i]
+		}
+		fields := strings.Fields(line)
+		if len(fields) == 0 {
+			continue
+		}
+
+		if block != "" {
+			if fields[0] == ")" {
+				block = ""
+				continue
+			}
+			mod.addDirective(block, fields)
+			continue
+		}
+
+		if len(fields) == 2 && fields[1] == "(" {
+			block = fields[0]
+			continue
+		}
+		mod.addDirective(fields[0], fields[1:])
+	}
+
+	return mod, scanner.Err()
+}
+
+func (m *goModule) addDirective(verb string, args []string) {
+	if len(args) == 0 {
+		return
+	}
+
+	switch verb {
+	case "module":
+		m.Path = strings.Trim(args[0], `"`)
+	case "require":
+		m.Requires = append(m.Requires, args[0])
+	case "replace":
+		for i, arg := range args {
+			if arg == "=>" && i+1 < len(args) {
+				target := args[i+1]
+				if strings.HasPrefix(target, "./") || strings.HasPrefix(target, "../") {
+					m.LocalReplaces = append(m.LocalReplaces, target)
+				}
+				break
+			}
+		}
+	}
+}
+
+// Router assigns commits to packages based on their conventional commit scope
+type Router struct {
+	cfg *config.Config
+}
+
+// NewRouter creates a router for the scope mapping of the given config
+func NewRouter(cfg *config.Config) *Router {
+	return &Router{cfg: cfg}
+}
+
+// Enabled reports whether scope routing is configured
+func (r *Router) Enabled() bool {
+	return len(r.cfg.Scopes) > 0
+}
+
+// Belongs reports whether a commit with the given scope belongs to the named
+// package. touchesPath tells whether the commit changed files under the
+// package path and is used for commits without a mapped scope.
+func (r *Router) Belongs(pkg, scope string, touchesPath bool) bool {
+	if target, ok := r.cfg.ScopePackage(scope); ok {
+		return target == pkg
+	}
+
+	switch r.cfg.Unscoped {
+	case config.UnscopedAll:
+		return true
+	case config.UnscopedNone:
+		return false
+	default:
+		return touchesPath
+	}
+}
@@ -0,0 +1,182 @@
+package monorepo
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/TheScenery/sem-version/internal/config"
+	"github.com/TheScenery/sem-version/pkg/version"
+)
+
+func TestPropagate(t *testing.T) {
+	packages := []config.Package{
+		{Name: "app", Path: "app", DependsOn: []string{"api"}},
+		{Name: "api", Path: "api", DependsOn: []string{"core"}},
+		{Name: "core", Path: "core"},
+		{Name: "docs", Path: "docs"},
+	}
+
+	g, err := NewGraph(t.TempDir(), packages)
+	if err != nil {
+		t.Fatalf("NewGraph() error = %v", err)
+	}
+
+	wantOrder := []string{"core", "api", "app", "docs"}
+	if got := g.Order(); !reflect.DeepEqual(got, wantOrder) {
+		t.Errorf("Order() = %v, want %v", got, wantOrder)
+	}
+
+	tests := []struct {
+		name  string
+		bumps map[string]version.BumpType
+		want  map[string]Propagation
+	}{
+		{
+			name:  "transitive propagation",
+			bumps: map[string]version.BumpType{"core": version.BumpMinorType},
+			want: map[string]Propagation{
+				"core": {Bump: version.BumpMinorType},
+				"api":  {Bump: version.BumpPatchType, Causes: []string{"core"}},
+				"app":  {Bump: version.BumpPatchType, Causes: []string{"api"}},
+				"docs": {Bump: version.BumpNone},
+			},
+		},
+		{
+			name: "own bump is kept",
+			bumps: map[string]version.BumpType{
+				"core": version.BumpPatchType,
+				"api":  version.BumpMajorType,
+			},
+			want: map[string]Propagation{
+				"core": {Bump: version.BumpPatchType},
+				"api":  {Bump: version.BumpMajorType},
+				"app":  {Bump: version.BumpPatchType, Causes: []string{"api"}},
+				"docs": {Bump: version.BumpNone},
+			},
+		},
+		{
+			name:  "no bumps",
+			bumps: map[string]version.BumpType{},
+			want: map[string]Propagation{
+				"core": {Bump: version.BumpNone},
+				"api":  {Bump: version.BumpNone},
+				"app":  {Bump: version.BumpNone},
+				"docs": {Bump: version.BumpNone},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := g.Propagate(tt.bumps); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Propagate() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNewGraph_Cycle(t *testing.T) {
+	packages := []config.Package{
+		{Name: "a", Path: "a", DependsOn: []string{"b"}},
+		{Name: "b", Path: "b", DependsOn: []string{"a"}},
+	}
+
+	if _, err := NewGraph(t.TempDir(), packages); err == nil {
+		t.Error("Expected error for dependency cycle")
+	}
+}
+
+func TestNewGraph_GoModules(t *testing.T) {
+	root := t.TempDir()
+	files := map[string]string{
+		"lib/go.mod": "module example.com/lib\n\ngo 1.25\n",
+		"svc/go.mod": `module example.com/svc
+
+go 1.25
+
+require (
+	example.com/lib v0.0.0 // indirect
+	gopkg.in/yaml.v3 v3.0.1
+)
+`,
+		"tool/go.mod": `module example.com/tool
+
+go 1.25
+
+replace example.com/svc => ../svc
+`,
+	}
+	for name, content := range files {
+		path := filepath.Join(root, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatalf("Failed to create dir: %v", err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatalf("Failed to write %s: %v", name, err)
+		}
+	}
+
+	packages := []config.Package{
+		{Name: "tool", Path: "tool"},
+		{Name: "svc", Path: "svc"},
+		{Name: "lib", Path: "lib"},
+	}
+
+	g, err := NewGraph(root, packages)
+	if err != nil {
+		t.Fatalf("NewGraph() error = %v", err)
+	}
+
+	if got := g.DependsOn("svc"); !reflect.DeepEqual(got, []string{"lib"}) {
+		t.Errorf("DependsOn(svc) = %v, want [lib]", got)
+	}
+	if got := g.DependsOn("tool"); !reflect.DeepEqual(got, []string{"svc"}) {
+		t.Errorf("DependsOn(tool) = %v, want [svc]", got)
+	}
+
+	wantOrder := []string{"lib", "svc", "tool"}
+	if got := g.Order(); !reflect.DeepEqual(got, wantOrder) {
+		t.Errorf("Order() = %v, want %v", got, wantOrder)
+	}
+}
+
+func TestRouter(t *testing.T) {
+	packages := []config.Package{
+		{Name: "api", Path: "packages/api"},
+		{Name: "web", Path: "packages/web"},
+	}
+
+	tests := []struct {
+		name     string
+		unscoped string
+		scope    string
+		touches  bool
+		want     bool
+	}{
+		{"mapped scope to package", "", "api", false, true},
+		{"mapped scope to other package", "", "web", true, false},
+		{"unmapped scope falls back to paths", "", "shared", true, true},
+		{"unscoped outside path", config.UnscopedPaths, "", false, false},
+		{"unscoped with all", config.UnscopedAll, "", false, true},
+		{"unscoped with none", config.UnscopedNone, "", true, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := &config.Config{
+				Packages: packages,
+				Scopes:   map[string]string{"api": "packages/api", "web": "web"},
+				Unscoped: tt.unscoped,
+			}
+			r := NewRouter(cfg)
+			if !r.Enabled() {
+				t.Fatal("Expected router to be enabled")
+			}
+			if got := r.Belongs("api", tt.scope, tt.touches); got != tt.want {
+				t.Errorf("Belongs() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,130 @@
+package pathfilter
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// Filter selects repository files with include and exclude globs
+//
+// Globs are matched against slash-separated paths relative to the
+// repository root: * matches within a path segment, ** across segments,
+// ? matches one character. A glob without a slash, such as "*.md", matches
+// the file name at any depth, and a glob ending with a slash, such as
+// "docs/", matches everything below that directory.
+type Filter struct {
+	include []*regexp.Regexp
+	exclude []*regexp.Regexp
+}
+
+// New compiles the include and exclude globs
+// Without include globs, every file not excluded is selected
+func New(include, exclude []string) (*Filter, error) {
+	f := &Filter{}
+	var err error
+
+	f.include, err = compileGlobs(include)
+	if err != nil {
+		return nil, fmt.Errorf("include_paths: %w", err)
+	}
+	f.exclude, err = compileGlobs(exclude)
+	if err != nil {
+		return nil, fmt.Errorf("exclude_paths: %w", err)
+	}
+	return f, nil
+}
+
+// ValidateGlob checks the syntax of a single glob
+func ValidateGlob(glob string) error {
+	_, err := compileGlob(glob)
+	return err
+}
+
+// Enabled reports whether the filter has any glob
+func (f *Filter) Enabled() bool {
+	return len(f.include) > 0 || len(f.exclude) > 0
+}
+
+// Match reports whether the file is selected: it matches an include glob,
+// or there is none, and no exclude glob
+func (f *Filter) Match(file string) bool {
+	if len(f.include) > 0 && !matchAny(f.include, file) {
+		return false
+	}
+	return !matchAny(f.exclude, file)
+}
+
+// MatchAny reports whether at least one of the files is selected
+func (f *Filter) MatchAny(files []string) bool {
+	for _, file := range files {
+		if f.Match(file) {
+			return true
+		}
+	}
+	return false
+}
+
+func matchAny(regexes []*regexp.Regexp, file string) bool {
+	for _, re := range regexes {
+		if re.MatchString(file) {
+			return true
+		}
+	}
+	return false
+}
+
+func compileGlobs(globs []string) ([]*regexp.Regexp, error) {
+	regexes := make([]*regexp.Regexp, 0, len(globs))
+	for _, glob := range globs {
+		re, err := compileGlob(glob)
+		if err != nil {
+			return nil, err
+		}
+		regexes = append(regexes, re)
+	}
+	return regexes, nil
+}
+
+// compileGlob converts a glob into an anchored regexp
+func compileGlob(glob string) (*regexp.Regexp, error) {
+	g := strings.TrimPrefix(glob, "/")
+	if g == "" {
+		return nil, fmt.Errorf("empty glob")
+	}
+
+	var b strings.Builder
+	b.WriteString("^")
+	if !strings.Contains(strings.TrimSuffix(g, "/"), "/") {
+		// A file name matches at any depth
+		b.WriteString("(?:.*/)?")
+	}
+
+	for i := 0; i < len(g); i++ {
+		switch c := g[i]; {
+		case c == '*' && strings.HasPrefix(g[i:], "**/"):
+			b.WriteString("(?:.*/)?")
+			i += 2
+		case c == '*' && strings.HasPrefix(g[i:], "**"):
+			b.WriteString(".*")
+			i++
+		case c == '*':
+			b.WriteString("[^/]*")
+		case c == '?':
+			b.WriteString("[^/]")
+		default:
+			b.WriteString(regexp.QuoteMeta(string(c)))
+		}
+	}
+
+	if strings.HasSuffix(g, "/") {
+		b.WriteString(".*")
+	}
+	b.WriteString("$")
+
+	re, err := regexp.Compile(b.String())
+	if err != nil {
+		return nil, fmt.Errorf("invalid glob %q: %w", glob, err)
+	}
+	return re, nil
+}
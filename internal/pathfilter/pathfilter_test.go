@@ -0,0 +1,62 @@
+package pathfilter
+
+import (
+	"testing"
+)
+
+func TestFilter_Match(t *testing.T) {
+	tests := []struct {
+		name    string
+		include []string
+		exclude []string
+		file    string
+		want    bool
+	}{
+		{name: "no globs", file: "main.go", want: true},
+		{name: "excluded directory", exclude: []string{"docs/"}, file: "docs/guide/intro.md", want: false},
+		{name: "directory prefix only", exclude: []string{"docs/"}, file: "cmd/docs.go", want: true},
+		{name: "double star", exclude: []string{".github/**"}, file: ".github/workflows/ci.yml", want: false},
+		{name: "test files anywhere", exclude: []string{"*_test.go"}, file: "internal/git/git_test.go", want: false},
+		{name: "star stays in segment", exclude: []string{"internal/*.go"}, file: "internal/git/git.go", want: true},
+		{name: "leading double star", exclude: []string{"**/testdata/**"}, file: "pkg/a/testdata/in.json", want: false},
+		{name: "included", include: []string{"src/**"}, file: "src/app/main.go", want: true},
+		{name: "not included", include: []string{"src/**"}, file: "README.md", want: false},
+		{name: "included but excluded", include: []string{"src/**"}, exclude: []string{"*.md"}, file: "src/README.md", want: false},
+		{name: "question mark", include: []string{"v?.txt"}, file: "v1.txt", want: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f, err := New(tt.include, tt.exclude)
+			if err != nil {
+				t.Fatalf("New() error = %v", err)
+			}
+			if got := f.Match(tt.file); got != tt.want {
+				t.Errorf("Match(%q) = %v, want %v", tt.file, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFilter_MatchAny(t *testing.T) {
+	f, err := New(nil, []string{"docs/", "*.md"})
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+
+	if f.MatchAny([]string{"docs/a.md", "README.md"}) {
+		t.Error("MatchAny() = true for only excluded files")
+	}
+	if !f.MatchAny([]string{"docs/a.md", "main.go"}) {
+		t.Error("MatchAny() = false with an included file")
+	}
+	if f.MatchAny(nil) {
+		t.Error("MatchAny() = true without files")
+	}
+}
+
+func TestNew_Invalid(t *testing.T) {
+	if _, err := New([]string{""}, nil); err == nil {
+		t.Error("New() expected error for an empty glob")
+	}
+}
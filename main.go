@@ -1,199 +1,419 @@
 package main
 
 import (
+	"context"
+	"errors"
 	"flag"
 	"fmt"
 	"os"
 	"path/filepath"
+	"strings"
+	"time"
 
+	"github.com/TheScenery/sem-version/internal/bumpfile"
 	"github.com/TheScenery/sem-version/internal/config"
-	"github.com/TheScenery/sem-version/internal/git"
-	"github.com/TheScenery/sem-version/internal/version"
+	"github.com/TheScenery/sem-version/pkg/semver"
 )
 
+// command is a sem-version subcommand
+type command struct {
+	name    string
+	summary string
+	run     func(args []string) error
+}
+
+// commands lists the subcommands in help order
+var commands = []command{
+	{"next", "Print the next version (default command)", runNext},
+	{"current", "Print the current version", runCurrent},
+	{"init", "Generate a default .sem-version.yaml", runInit},
+	{"tag", "Create a git tag for the next version", runTag},
+	{"changelog", "Print a Markdown changelog of unreleased commits", runChangelog},
+	{"lint", "Check that commit messages follow Conventional Commits", runLint},
+	{"explain", "Explain how the next version is computed", runExplain},
+	{"config", "Validate or test the configuration (config validate|test)", runConfig},
+	{"generate", "Generate a Go source file with version constants", runGenerate},
+	{"ldflags", "Print -X flags for go build", runLdflags},
+	{"satisfies", "Check that a version satisfies a constraint", runSatisfies},
+	{"cache", "Manage the commit cache (cache clear)", runCache},
+}
+
 func main() {
-	// Parse command line flags
-	prefix := flag.String("prefix", "v", "Version prefix (default: v)")
-	repoPath := flag.String("path", ".", "Path to git repository (default: current directory)")
-	configPath := flag.String("config", "", "Path to config file (default: auto-detect .sem-version.yaml)")
-	noPrefix := flag.Bool("no-prefix", false, "Output version without prefix")
-	verbose := flag.Bool("verbose", false, "Show verbose output")
-	initConfig := flag.Bool("init", false, "Generate default config file")
-	flag.Parse()
-
-	// Resolve absolute path
-	absPath, err := filepath.Abs(*repoPath)
-	if err != nil {
-		fmt.Fprintf(os.Stderr, "Error resolving path: %v\n", err)
-		os.Exit(1)
-	}
+	args := os.Args[1:]
 
-	// Handle --init command
-	if *initConfig {
-		if err := generateDefaultConfig(absPath); err != nil {
-			fmt.Fprintf(os.Stderr, "Error generating config: %v\n", err)
-			os.Exit(1)
+	// Without a subcommand, behave like `next` for backward compatibility
+	run := runNext
+	if len(args) > 0 && !strings.HasPrefix(args[0], "-") {
+		name := args[0]
+		if name == "help" {
+			printUsage()
+			return
 		}
-		fmt.Println("Generated .sem-version.yaml")
-		return
-	}
 
-	// Load configuration
-	var cfg *config.Config
-	if *configPath != "" {
-		cfg, err = config.Load(*configPath)
-		if err != nil {
-			fmt.Fprintf(os.Stderr, "Error loading config %s: %v\n", *configPath, err)
-			os.Exit(1)
+		found := false
+		for _, c := range commands {
+			if c.name == name {
+				run, found = c.run, true
+				break
+			}
 		}
-		if *verbose {
-			fmt.Fprintf(os.Stderr, "Using config: %s\n", *configPath)
-		}
-	} else {
-		cfg, err = config.LoadDefault(absPath)
-		if err != nil {
-			fmt.Fprintf(os.Stderr, "Error loading config: %v\n", err)
-			os.Exit(1)
+		if !found {
+			fmt.Fprintf(os.Stderr, "Unknown command: %s\n\n", name)
+			printUsage()
+			os.Exit(exitUsage)
 		}
+		args = args[1:]
+	} else if len(args) > 0 && (args[0] == "-h" || args[0] == "--help") {
+		printUsage()
+		return
+	}
+
+	if err := run(args); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(exitCode(err))
+	}
+}
+
+// Exit codes, documented in the README
+const (
+	exitError          = 1
+	exitUsage          = 2
+	exitNotARepository = 3
+	exitNoCommits      = 4
+	exitGitNotFound    = 5
+	exitShallow        = 6
+	exitTimeout        = 7
+	exitNotSatisfied   = 8
+)
+
+// usageError reports missing or invalid command arguments
+type usageError string
+
+func (e usageError) Error() string {
+	return string(e)
+}
+
+// exitCode returns the exit code reporting err
+func exitCode(err error) int {
+	switch {
+	case errors.Is(err, semver.ErrNotARepository):
+		return exitNotARepository
+	case errors.Is(err, semver.ErrNoCommits):
+		return exitNoCommits
+	case errors.Is(err, semver.ErrGitNotFound):
+		return exitGitNotFound
+	case errors.Is(err, semver.ErrShallow):
+		return exitShallow
+	case errors.Is(err, context.DeadlineExceeded):
+		return exitTimeout
+	case errors.Is(err, errNotSatisfied):
+		return exitNotSatisfied
+	case errors.As(err, new(usageError)):
+		return exitUsage
+	default:
+		return exitError
+	}
+}
+
+func printUsage() {
+	fmt.Fprintln(os.Stderr, "Usage: sem-version [command] [flags]")
+	fmt.Fprintln(os.Stderr, "\nCommands:")
+	for _, c := range commands {
+		fmt.Fprintf(os.Stderr, "  %-10s %s\n", c.name, c.summary)
+	}
+	fmt.Fprintln(os.Stderr, "\nRun 'sem-version <command> -h' for the flags of a command.")
+}
+
+// newFlagSet creates the flag set of a subcommand with a usage header
+func newFlagSet(name, usage, summary string) *flag.FlagSet {
+	fs := flag.NewFlagSet(name, flag.ExitOnError)
+	fs.Usage = func() {
+		fmt.Fprintf(fs.Output(), "Usage: sem-version %s\n\n%s\n\nFlags:\n", usage, summary)
+		fs.PrintDefaults()
 	}
+	return fs
+}
+
+// stringList is a flag.Value collecting repeated string flags
+type stringList []string
+
+func (s *stringList) String() string {
+	return strings.Join(*s, ",")
+}
+
+func (s *stringList) Set(value string) error {
+	*s = append(*s, value)
+	return nil
+}
 
-	// Get the latest tag
-	latestTag, err := git.GetLatestTag(absPath)
+// globalOptions holds the flags shared by commands reading the repository
+type globalOptions struct {
+	path        string
+	config      string
+	branch      string
+	pathFilters stringList
+	deepen      int
+	jobs        int
+	timeout     time.Duration
+	noCache     bool
+	verbose     bool
+}
+
+// addGlobalFlags registers the shared repository flags on fs
+func addGlobalFlags(fs *flag.FlagSet) *globalOptions {
+	o := &globalOptions{}
+	fs.StringVar(&o.path, "path", ".", "Path to git repository (default: current directory)")
+	fs.StringVar(&o.config, "config", "", "Path to config file (default: auto-detect .sem-version.yaml)")
+	fs.StringVar(&o.branch, "branch", "", "Branch selecting the release policy (default: checked out branch)")
+	fs.Var(&o.pathFilters, "path-filter", "Glob of the files whose changes count, or !glob of ignored files (e.g. '!docs/'); repeatable")
+	fs.IntVar(&o.deepen, "deepen", 0, "In a shallow clone, fetch this many commits at a time until a version tag is reachable (default: fail)")
+	fs.IntVar(&o.jobs, "jobs", 0, "Maximum number of packages computed and git processes run concurrently (default: number of CPUs)")
+	fs.DurationVar(&o.timeout, "timeout", 0, "Abort git operations after this duration, e.g. 30s (default: no timeout)")
+	fs.BoolVar(&o.noCache, "no-cache", false, "Do not read or write the commit cache in .git/sem-version/")
+	fs.BoolVar(&o.verbose, "verbose", false, "Show verbose output")
+	return o
+}
+
+// context returns the context of a command, done after --timeout
+func (o *globalOptions) context() (context.Context, context.CancelFunc) {
+	if o.timeout > 0 {
+		return context.WithTimeout(context.Background(), o.timeout)
+	}
+	return context.WithCancel(context.Background())
+}
+
+// repoPath returns the root of the repository containing --path, such as
+// when run by go:generate in a package directory. Outside a repository, it
+// is --path itself, for the commands that only read the config.
+func (o *globalOptions) repoPath(ctx context.Context) (string, error) {
+	absPath, err := filepath.Abs(o.path)
 	if err != nil {
-		fmt.Fprintf(os.Stderr, "Error getting latest tag: %v\n", err)
-		os.Exit(1)
+		return "", fmt.Errorf("resolving path: %w", err)
 	}
 
-	var currentVersion version.Version
-	if latestTag == "" {
-		// No tags found, use initial version v0.0.0
-		currentVersion = version.Version{Major: 0, Minor: 0, Patch: 0}
-		if *verbose {
-			fmt.Fprintln(os.Stderr, "No existing tags found, starting from v0.0.0")
-		}
-	} else {
-		currentVersion, err = version.Parse(latestTag)
+	root, err := semver.RepositoryRoot(ctx, absPath)
+	if errors.Is(err, semver.ErrNotARepository) || errors.Is(err, semver.ErrGitNotFound) {
+		return absPath, nil
+	}
+	return root, o.timedOut(err)
+}
+
+// timedOut names the --timeout in the error of an aborted git command
+func (o *globalOptions) timedOut(err error) error {
+	if errors.Is(err, context.DeadlineExceeded) {
+		return fmt.Errorf("timed out after %s: %w", o.timeout, err)
+	}
+	return err
+}
+
+// loadConfig loads the config file given by --config, or auto-detects it
+func (o *globalOptions) loadConfig(ctx context.Context) (*config.Config, error) {
+	repoPath, err := o.repoPath(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	if o.config == "" {
+		cfg, err := config.LoadDefault(repoPath)
 		if err != nil {
-			fmt.Fprintf(os.Stderr, "Error parsing version %s: %v\n", latestTag, err)
-			os.Exit(1)
-		}
-		if *verbose {
-			fmt.Fprintf(os.Stderr, "Current version: %s\n", latestTag)
+			return nil, fmt.Errorf("loading config: %w", err)
 		}
+		o.applyPathFilters(cfg)
+		return cfg, nil
 	}
 
-	// Get commits since last tag
-	commits, err := git.GetCommitsSince(absPath, latestTag)
+	cfg, err := config.Load(o.config)
 	if err != nil {
-		fmt.Fprintf(os.Stderr, "Error getting commits: %v\n", err)
-		os.Exit(1)
+		// Errors of the config name the file
+		return nil, fmt.Errorf("loading config: %w", err)
+	}
+	if o.verbose {
+		fmt.Fprintf(os.Stderr, "Using config: %s\n", o.config)
 	}
+	o.applyPathFilters(cfg)
+	return cfg, nil
+}
 
-	if len(commits) == 0 {
-		if *verbose {
-			fmt.Fprintln(os.Stderr, "No new commits since last tag")
-		}
-		// If no commits and no tag, output initial version
-		if latestTag == "" {
-			outputVersion(version.DefaultInitialVersion(), *prefix, *noPrefix)
+// applyPathFilters adds the --path-filter globs to the config path filters
+func (o *globalOptions) applyPathFilters(cfg *config.Config) {
+	for _, f := range o.pathFilters {
+		if exclude, ok := strings.CutPrefix(f, "!"); ok {
+			cfg.ExcludePaths = append(cfg.ExcludePaths, exclude)
 		} else {
-			outputVersion(currentVersion, *prefix, *noPrefix)
+			cfg.IncludePaths = append(cfg.IncludePaths, f)
 		}
-		return
 	}
+}
 
-	if *verbose {
-		fmt.Fprintf(os.Stderr, "Found %d commits since last tag\n", len(commits))
+// compute loads the config and runs the version computation
+func (o *globalOptions) compute(ctx context.Context) (semver.Result, *config.Config, error) {
+	repoPath, err := o.repoPath(ctx)
+	if err != nil {
+		return semver.Result{}, nil, err
 	}
 
-	// Analyze commits using config
-	bumpType := version.BumpNone
-	for _, commit := range commits {
-		// Get full commit message for better matching
-		fullMessage, err := git.GetFullCommitMessage(absPath, commit.Hash)
-		if err != nil {
-			fullMessage = commit.Message
-		}
+	cfg, err := o.loadConfig(ctx)
+	if err != nil {
+		return semver.Result{}, nil, err
+	}
 
-		// Check patterns in order of priority: major > minor > patch
-		if cfg.MatchMajor(fullMessage) {
-			bumpType = version.BumpMajorType
-			if *verbose {
-				fmt.Fprintf(os.Stderr, "  - [MAJOR] %s\n", commit.Message)
-			}
-			break // Major is highest priority
-		}
+	res, err := semver.Compute(ctx, repoPath, semver.Options{
+		Config: cfg,
+		Branch: o.branch,
+		Deepen: o.deepen,
+		Jobs:   o.jobs,
+		Cache:  !o.noCache,
+	})
+	if err != nil {
+		return semver.Result{}, nil, o.timedOut(err)
+	}
 
-		if cfg.MatchMinor(fullMessage) && bumpType < version.BumpMinorType {
-			bumpType = version.BumpMinorType
-			if *verbose {
-				fmt.Fprintf(os.Stderr, "  - [MINOR] %s\n", commit.Message)
-			}
-		} else if cfg.MatchPatch(fullMessage) && bumpType < version.BumpPatchType {
-			bumpType = version.BumpPatchType
-			if *verbose {
-				fmt.Fprintf(os.Stderr, "  - [PATCH] %s\n", commit.Message)
-			}
-		} else if *verbose {
-			fmt.Fprintf(os.Stderr, "  - [SKIP] %s\n", commit.Message)
-		}
+	if o.verbose && res.Policy != nil {
+		fmt.Fprintf(os.Stderr, "Branch: %s (%s)\n", res.Branch, describePolicy(res.Branch, *res.Policy))
+	}
+	if o.verbose && len(res.Packages) == 0 {
+		printAnalysis(res.Tag, res.Commits)
 	}
+	return res, cfg, nil
+}
+
+// describePolicy returns a short description of a branch release policy
+func describePolicy(branch string, p semver.BranchPolicy) string {
+	desc := "stable releases"
+	if p.Prerelease != "" {
+		desc = "prereleases -" + p.PrereleaseFor(branch) + ".N"
+	}
+	if c, err := p.Constraint(); err == nil && c != nil {
+		desc += ", range " + c.String()
+	}
+	return "policy " + p.Name + ": " + desc
+}
 
-	// Calculate next version
-	var nextVersion version.Version
-	if latestTag == "" {
-		// No existing tag - determine initial version based on bump type
-		nextVersion = calculateInitialVersion(bumpType)
+// printAnalysis prints the latest tag and the classification of each commit to stderr
+func printAnalysis(tag string, commits []semver.Commit) {
+	if tag == "" {
+		fmt.Fprintln(os.Stderr, "No existing tags found, starting from v0.0.0")
 	} else {
-		nextVersion = applyBump(currentVersion, bumpType)
+		fmt.Fprintf(os.Stderr, "Current version: %s\n", tag)
 	}
 
-	outputVersion(nextVersion, *prefix, *noPrefix)
+	if len(commits) == 0 {
+		fmt.Fprintln(os.Stderr, "No new commits since last tag")
+		return
+	}
+
+	fmt.Fprintf(os.Stderr, "Found %d commits since last tag\n", len(commits))
+	printCommits(commits)
 }
 
-// calculateInitialVersion determines the initial version based on bump type
-func calculateInitialVersion(bumpType version.BumpType) version.Version {
-	switch bumpType {
-	case version.BumpMajorType:
-		return version.Version{Major: 1, Minor: 0, Patch: 0}
-	case version.BumpMinorType:
-		return version.Version{Major: 0, Minor: 1, Patch: 0}
-	case version.BumpPatchType:
-		return version.Version{Major: 0, Minor: 0, Patch: 1}
-	default:
-		return version.Version{Major: 0, Minor: 1, Patch: 0}
+// printCommits prints the classification of each commit to stderr
+func printCommits(commits []semver.Commit) {
+	for _, c := range commits {
+		fmt.Fprintf(os.Stderr, "  - [%s] %s%s\n", commitLabel(c), c.Subject, revertNote(c))
 	}
 }
 
-// applyBump applies the bump type to the current version
-func applyBump(current version.Version, bumpType version.BumpType) version.Version {
-	switch bumpType {
-	case version.BumpMajorType:
-		return current.BumpMajor()
-	case version.BumpMinorType:
-		return current.BumpMinor()
-	case version.BumpPatchType:
-		return current.BumpPatch()
-	default:
-		return current
+// bumpLabel returns the uppercase label of a bump type, SKIP for no bump
+func bumpLabel(b semver.BumpType) string {
+	if b == semver.BumpNone {
+		return "SKIP"
 	}
+	return strings.ToUpper(b.String())
 }
 
-func outputVersion(v version.Version, prefix string, noPrefix bool) {
-	if noPrefix {
-		fmt.Printf("%d.%d.%d\n", v.Major, v.Minor, v.Patch)
-	} else {
-		fmt.Printf("%s%d.%d.%d\n", prefix, v.Major, v.Minor, v.Patch)
+// commitLabel returns the bump label of a commit, REVERTED for commits
+// cancelled by a revert and EXCLUDED for commits left out by path filters
+func commitLabel(c semver.Commit) string {
+	if c.Cancelled() {
+		return "REVERTED"
+	}
+	if c.Excluded {
+		return "EXCLUDED"
 	}
+	return bumpLabel(c.Bump)
 }
 
-func generateDefaultConfig(dir string) error {
-	configPath := filepath.Join(dir, ".sem-version.yaml")
+// revertNote describes the revert pairing of a commit, e.g. " (reverted by 1a2b3c4)"
+func revertNote(c semver.Commit) string {
+	switch {
+	case c.RevertedBy != "":
+		return " (reverted by " + c.RevertedBy[:7] + ")"
+	case c.Reverts != "":
+		return " (reverts " + c.Reverts[:7] + ")"
+	}
+	return ""
+}
+
+// addMetadata appends the rendered build metadata template to v
+func addMetadata(v semver.Version, res semver.Result, tmpl string) (semver.Version, error) {
+	if tmpl == "" {
+		return v, nil
+	}
+
+	now, err := buildTime()
+	if err != nil {
+		return v, err
+	}
 
-	// Check if file exists
-	if _, err := os.Stat(configPath); err == nil {
-		return fmt.Errorf("config file already exists: %s", configPath)
+	metadata, err := semver.RenderMetadata(tmpl, res.MetadataData(now))
+	if err != nil {
+		return v, fmt.Errorf("rendering --metadata: %w", err)
 	}
+	return semver.WithMetadata(v, metadata), nil
+}
 
-	return os.WriteFile(configPath, []byte(config.DefaultConfigYAML()), 0644)
+// selectPackage returns the current and next versions of the package named
+// by --package-name in monorepo mode, or of the repository without packages
+func selectPackage(res semver.Result, name string) (current, next semver.Version, err error) {
+	if len(res.Packages) == 0 {
+		if name != "" {
+			return semver.Version{}, semver.Version{}, usageError(fmt.Sprintf("--package-name %s: no packages are configured", name))
+		}
+		return res.Current, res.Next, nil
+	}
+
+	names := make([]string, 0, len(res.Packages))
+	for _, p := range res.Packages {
+		if p.Package.Name == name {
+			return p.Current, p.Next, nil
+		}
+		names = append(names, p.Package.Name)
+	}
+	if name == "" {
+		return semver.Version{}, semver.Version{}, usageError(fmt.Sprintf("packages are configured, select one with --package-name (%s)", strings.Join(names, ", ")))
+	}
+	return semver.Version{}, semver.Version{}, usageError(fmt.Sprintf("unknown package %q (want %s)", name, strings.Join(names, ", ")))
+}
+
+func outputVersion(v semver.Version, prefix string, noPrefix bool) {
+	fmt.Println(formatVersion(v, prefix, noPrefix))
+}
+
+func formatVersion(v semver.Version, prefix string, noPrefix bool) string {
+	if noPrefix {
+		return v.StringWithoutPrefix()
+	}
+	return prefix + v.StringWithoutPrefix()
+}
+
+// updateBumpFiles rewrites files with the version, or prints diffs to stderr in dry-run mode
+func updateBumpFiles(root string, files []config.BumpFile, v semver.Version, dryRun bool) error {
+	for _, file := range files {
+		change, err := bumpfile.Update(root, file, formatVersion(v, "", true))
+		if err != nil {
+			return err
+		}
+		if !change.Changed() {
+			continue
+		}
+
+		if dryRun {
+			fmt.Fprint(os.Stderr, change.Diff(filepath.ToSlash(file.Path)))
+			continue
+		}
+		if err := change.Write(); err != nil {
+			return err
+		}
+	}
+	return nil
 }
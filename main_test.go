@@ -0,0 +1,122 @@
+package main
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+	"time"
+
+	"github.com/TheScenery/sem-version/pkg/semver"
+)
+
+func TestExitCode_Timeout(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("needs a shell script as git")
+	}
+	// A git command hanging on the network
+	bin := t.TempDir()
+	if err := os.WriteFile(filepath.Join(bin, "git"), []byte("#!/bin/sh\nsleep 10 &\nwait\n"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("PATH", bin+string(os.PathListSeparator)+os.Getenv("PATH"))
+
+	opts := &globalOptions{path: t.TempDir(), timeout: 100 * time.Millisecond, noCache: true}
+	ctx, cancel := opts.context()
+	defer cancel()
+
+	_, _, err := opts.compute(ctx)
+	if err == nil {
+		t.Fatal("compute() error = nil, want a timeout")
+	}
+	if got := exitCode(err); got != exitTimeout {
+		t.Errorf("exitCode(%v) = %d, want %d", err, got, exitTimeout)
+	}
+}
+
+func TestExitCode_Satisfies(t *testing.T) {
+	tests := []struct {
+		name string
+		args []string
+		want int
+	}{
+		{name: "satisfied", args: []string{"1.4.2", "^1.4.0"}, want: 0},
+		{name: "not satisfied", args: []string{"2.0.0", "^1.4.0"}, want: exitNotSatisfied},
+		{name: "missing constraint", args: []string{"1.4.2"}, want: exitUsage},
+		{name: "invalid version", args: []string{"1.x.y", "^1.4.0"}, want: exitError},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := 0
+			if err := runSatisfies(tt.args); err != nil {
+				got = exitCode(err)
+			}
+			if got != tt.want {
+				t.Errorf("runSatisfies(%q) exit code = %d, want %d", tt.args, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestExitCode_Usage(t *testing.T) {
+	tests := []struct {
+		name string
+		run  func([]string) error
+		args []string
+	}{
+		{name: "ldflags without var", run: runLdflags},
+		{name: "generate without language", run: runGenerate},
+		{name: "generate to stdout without package", run: runGenerate, args: []string{"go"}},
+		{name: "cache without subcommand", run: runCache},
+		{name: "config without subcommand", run: runConfig},
+		{name: "config unknown subcommand", run: runConfig, args: []string{"check"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.run(tt.args)
+			if got := exitCode(err); err == nil || got != exitUsage {
+				t.Errorf("exit code = %d (%v), want %d", got, err, exitUsage)
+			}
+		})
+	}
+}
+
+func TestSelectPackage(t *testing.T) {
+	single := semver.Result{Next: semver.Version{Major: 1, Minor: 2}}
+	mono := semver.Result{Packages: []semver.PackageResult{
+		{Package: semver.Package{Name: "api"}, Next: semver.Version{Major: 2}},
+		{Package: semver.Package{Name: "web"}, Next: semver.Version{Minor: 3}},
+	}}
+
+	tests := []struct {
+		name    string
+		res     semver.Result
+		pkg     string
+		want    string
+		wantErr bool
+	}{
+		{name: "single", res: single, want: "v1.2.0"},
+		{name: "single with package", res: single, pkg: "api", wantErr: true},
+		{name: "package", res: mono, pkg: "web", want: "v0.3.0"},
+		{name: "missing package", res: mono, wantErr: true},
+		{name: "unknown package", res: mono, pkg: "cli", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, next, err := selectPackage(tt.res, tt.pkg)
+			if tt.wantErr {
+				if !errors.As(err, new(usageError)) {
+					t.Errorf("selectPackage() error = %v, want a usage error", err)
+				}
+				return
+			}
+			if err != nil || next.String() != tt.want {
+				t.Errorf("selectPackage() = %v, %v, want %s", next, err, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,82 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/TheScenery/sem-version/internal/config"
+	"github.com/TheScenery/sem-version/pkg/semver"
+)
+
+// packageOutput is the JSON representation of a package result
+type packageOutput struct {
+	Name           string   `json:"name"`
+	Path           string   `json:"path"`
+	Tag            string   `json:"tag,omitempty"`
+	Current        string   `json:"current"`
+	Next           string   `json:"next"`
+	Bump           string   `json:"bump"`
+	PropagatedFrom []string `json:"propagated_from,omitempty"`
+}
+
+// outputPackages prints the next version of every package and updates their bump files
+func outputPackages(repoPath string, packages []semver.PackageResult, prefix string, noPrefix, jsonOutput, verbose, writeFiles, dryRun bool) error {
+	outputs := make([]packageOutput, 0, len(packages))
+
+	for _, p := range packages {
+		if verbose {
+			fmt.Fprintf(os.Stderr, "Package %s: %d commits since %s\n", p.Package.Name, semver.CountCommits(p.Commits), describeTag(p.Tag))
+			printCommits(p.Commits)
+			if len(p.PropagatedFrom) > 0 {
+				fmt.Fprintf(os.Stderr, "  - [PATCH] dependency released: %s\n", strings.Join(p.PropagatedFrom, ", "))
+			}
+		}
+
+		if writeFiles || dryRun {
+			if err := updateBumpFiles(repoPath, packageBumpFiles(p.Package), p.Next, dryRun); err != nil {
+				return fmt.Errorf("updating files of %s: %w", p.Package.Name, err)
+			}
+		}
+
+		outputs = append(outputs, packageOutput{
+			Name:           p.Package.Name,
+			Path:           p.Package.Path,
+			Tag:            p.Tag,
+			Current:        formatVersion(p.Current, prefix, noPrefix),
+			Next:           formatVersion(p.Next, prefix, noPrefix),
+			Bump:           p.Bump.String(),
+			PropagatedFrom: p.PropagatedFrom,
+		})
+	}
+
+	if jsonOutput {
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		return enc.Encode(outputs)
+	}
+
+	for _, o := range outputs {
+		fmt.Printf("%s %s\n", o.Name, o.Next)
+	}
+	return nil
+}
+
+// packageBumpFiles returns the bump files of a package relative to the repository root
+func packageBumpFiles(pkg config.Package) []config.BumpFile {
+	files := make([]config.BumpFile, 0, len(pkg.BumpFiles))
+	for _, f := range pkg.BumpFiles {
+		f.Path = filepath.Join(pkg.Path, f.Path)
+		files = append(files, f)
+	}
+	return files
+}
+
+func describeTag(tag string) string {
+	if tag == "" {
+		return "the beginning"
+	}
+	return tag
+}
@@ -1,6 +1,9 @@
+// Package parser parses and validates Conventional Commits messages.
 package parser
 
 import (
+	"errors"
+	"fmt"
 	"regexp"
 	"strings"
 )
@@ -36,6 +39,9 @@ type ParsedCommit struct {
 // Pattern: type(scope)!: description
 var conventionalCommitRegex = regexp.MustCompile(`^(\w+)(?:\(([^)]*)\))?(!)?:\s*(.*)$`)
 
+// revertRegex matches the line `git revert` adds to the body of a revert commit
+var revertRegex = regexp.MustCompile(`(?m)^This reverts commit ([0-9a-f]{7,40})\b`)
+
 // ParseCommit parses a commit message according to Conventional Commits spec
 func ParseCommit(message string) ParsedCommit {
 	result := ParsedCommit{
@@ -80,6 +86,78 @@ func ParseCommit(message string) ParsedCommit {
 	return result
 }
 
+// Validate returns an error describing why the message does not follow the
+// Conventional Commits format, or nil if it does
+func Validate(message string) error {
+	subject := strings.TrimSpace(strings.SplitN(message, "\n", 2)[0])
+	if subject == "" {
+		return errors.New("empty commit message")
+	}
+
+	matches := conventionalCommitRegex.FindStringSubmatch(subject)
+	if matches == nil {
+		return errors.New("subject must have the form 'type(scope): description'")
+	}
+	if parseType(matches[1]) == TypeUnknown {
+		return fmt.Errorf("unknown commit type %q", matches[1])
+	}
+	if strings.TrimSpace(matches[4]) == "" {
+		return errors.New("description must not be empty")
+	}
+	return nil
+}
+
+// ParseRevert returns the (possibly abbreviated) hash of the commit reverted
+// by message, as written by `git revert`, or "" if message is not a revert
+func ParseRevert(message string) string {
+	matches := revertRegex.FindStringSubmatch(message)
+	if matches == nil {
+		return ""
+	}
+	return matches[1]
+}
+
+// squashBulletRegex matches a conventional commit listed as a bullet in the
+// body of a squash commit, e.g. "* feat(api): add endpoint"
+var squashBulletRegex = regexp.MustCompile(`^[*-]\s+(\w+(?:\([^)]*\))?!?:\s*\S.*)$`)
+
+// SplitSquash splits the body of a squash commit, which lists the squashed
+// commits as bullets ("* feat: a", "* fix: b"), into their messages. Lines
+// following a bullet, such as a BREAKING CHANGE footer, belong to it;
+// other bullets are dropped.
+// Returns nil if the body lists no conventional commit.
+func SplitSquash(message string) []string {
+	lines := strings.Split(message, "\n")
+
+	var messages []string
+	var current []string
+	flush := func() {
+		if current != nil {
+			messages = append(messages, strings.TrimSpace(strings.Join(current, "\n")))
+		}
+	}
+
+	// The subject is the title of the squash commit, not a squashed commit
+	for _, line := range lines[1:] {
+		line = strings.TrimSpace(line)
+		if strings.HasPrefix(line, "* ") || strings.HasPrefix(line, "- ") {
+			// Bullets that are not conventional commits, such as "* wip", are dropped
+			flush()
+			current = nil
+			if m := squashBulletRegex.FindStringSubmatch(line); m != nil {
+				current = []string{m[1]}
+			}
+			continue
+		}
+		if current != nil {
+			current = append(current, line)
+		}
+	}
+	flush()
+
+	return messages
+}
+
 // parseType converts a string to CommitType
 func parseType(t string) CommitType {
 	switch strings.ToLower(t) {
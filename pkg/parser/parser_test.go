@@ -141,3 +141,90 @@ func TestIsBumpType(t *testing.T) {
 		})
 	}
 }
+
+func TestValidate(t *testing.T) {
+	tests := []struct {
+		message string
+		wantErr bool
+	}{
+		{"feat: add login", false},
+		{"fix(core)!: drop support\n\nBREAKING CHANGE: gone", false},
+		{"bugfix: urgent", false},
+		{"", true},
+		{"add login", true},
+		{"feature add: login", true},
+		{"wip: stuff", true},
+		{"feat: ", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.message, func(t *testing.T) {
+			if err := Validate(tt.message); (err != nil) != tt.wantErr {
+				t.Errorf("Validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestParseRevert(t *testing.T) {
+	tests := []struct {
+		message string
+		want    string
+	}{
+		{"Revert \"feat: add login\"\n\nThis reverts commit 1a2b3c4d5e6f7a8b9c0d1a2b3c4d5e6f7a8b9c0d.", "1a2b3c4d5e6f7a8b9c0d1a2b3c4d5e6f7a8b9c0d"},
+		{"revert: feat: add login\n\nThis reverts commit 1a2b3c4.", "1a2b3c4"},
+		{"feat: add login", ""},
+		{"fix: typo\n\nSee This reverts commit 1a2b3c4", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.message, func(t *testing.T) {
+			if got := ParseRevert(tt.message); got != tt.want {
+				t.Errorf("ParseRevert() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSplitSquash(t *testing.T) {
+	tests := []struct {
+		name    string
+		message string
+		want    []string
+	}{
+		{
+			name:    "github squash",
+			message: "feat: login (#12)\n\n* feat: add form\n\n* fix(api): handle 401\n\n* wip",
+			want:    []string{"feat: add form", "fix(api): handle 401"},
+		},
+		{
+			name:    "footer belongs to bullet",
+			message: "Squashed\n\n- feat!: drop v1\n  BREAKING CHANGE: v1 is gone\n- docs: readme",
+			want:    []string{"feat!: drop v1\nBREAKING CHANGE: v1 is gone", "docs: readme"},
+		},
+		{
+			name:    "no bullets",
+			message: "feat: login\n\nAdds a login form.",
+			want:    nil,
+		},
+		{
+			name:    "subject only",
+			message: "* feat: not a body",
+			want:    nil,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := SplitSquash(tt.message)
+			if len(got) != len(tt.want) {
+				t.Fatalf("SplitSquash() = %q, want %q", got, tt.want)
+			}
+			for i := range got {
+				if got[i] != tt.want[i] {
+					t.Errorf("SplitSquash()[%d] = %q, want %q", i, got[i], tt.want[i])
+				}
+			}
+		})
+	}
+}
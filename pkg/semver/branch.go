@@ -0,0 +1,185 @@
+package semver
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/TheScenery/sem-version/internal/config"
+	"github.com/TheScenery/sem-version/internal/git"
+	"github.com/TheScenery/sem-version/pkg/version"
+)
+
+// BranchPolicy is a release policy applied to matching branches
+type BranchPolicy = config.Branch
+
+// resolveBranchPolicy returns the release policy of the branch, or nil when
+// the config declares no branch policies. An empty branch is detected from
+// the checkout.
+func resolveBranchPolicy(ctx context.Context, repoPath string, cfg *Config, branch string) (*BranchPolicy, string, error) {
+	if len(cfg.Branches) == 0 {
+		return nil, branch, nil
+	}
+
+	if branch == "" {
+		var err error
+		branch, err = git.GetCurrentBranch(ctx, repoPath)
+		if err != nil {
+			return nil, "", fmt.Errorf("getting current branch: %w", err)
+		}
+		if branch == "" {
+			return nil, "", fmt.Errorf("cannot determine branch: HEAD is detached, set the branch explicitly (--branch)")
+		}
+	}
+
+	policy, ok := cfg.BranchPolicy(branch)
+	if !ok {
+		return nil, "", fmt.Errorf("no release policy matches branch %s", branch)
+	}
+	return &policy, branch, nil
+}
+
+// PolicyViolationError reports a computed version that a branch release
+// policy does not allow
+type PolicyViolationError struct {
+	Version Version
+	Branch  string
+	Reason  string
+}
+
+func (e *PolicyViolationError) Error() string {
+	return fmt.Sprintf("version %s is not allowed on branch %s: %s", e.Version, e.Branch, e.Reason)
+}
+
+// applyBranchPolicy turns next into a prerelease of the branch when the
+// policy asks for it, then checks it against the allowed range and the
+// existing tags
+func applyBranchPolicy(ctx context.Context, repoPath string, policy BranchPolicy, branch, tagPrefix, tag string, current, next Version) (Version, error) {
+	// Nothing to release since the latest tag
+	if tag != "" && next == current {
+		return next, nil
+	}
+
+	if policy.Prerelease != "" {
+		pre := policy.PrereleaseFor(branch)
+
+		// A re-run on a commit already released as a prerelease of this
+		// version keeps its tag instead of stacking a new one
+		n, err := highestPrereleaseNumber(ctx, repoPath, "HEAD", tagPrefix, next, pre)
+		if err != nil {
+			return next, err
+		}
+		if n > 0 {
+			next.Prerelease = fmt.Sprintf("%s.%d", pre, n)
+			return next, checkConstraint(policy, branch, next)
+		}
+
+		n, err = highestPrereleaseNumber(ctx, repoPath, "", tagPrefix, next, pre)
+		if err != nil {
+			return next, err
+		}
+		next.Prerelease = fmt.Sprintf("%s.%d", pre, n+1)
+	}
+
+	if err := checkConstraint(policy, branch, next); err != nil {
+		return next, err
+	}
+
+	existing, err := findTag(ctx, repoPath, tagPrefix, next)
+	if err != nil {
+		return next, err
+	}
+	if existing != "" {
+		return next, &PolicyViolationError{Version: next, Branch: branch, Reason: "tag " + existing + " already exists"}
+	}
+
+	return next, nil
+}
+
+// findTag returns the tag of version v anywhere in the repository, ignoring
+// build metadata, or an empty string if v was never tagged
+func findTag(ctx context.Context, repoPath, tagPrefix string, v Version) (string, error) {
+	tags, err := git.ListTags(ctx, repoPath, tagPrefix+v.String()+"*")
+	if err != nil {
+		return "", fmt.Errorf("listing tags: %w", err)
+	}
+
+	for _, tag := range tags {
+		t, err := version.Parse(strings.TrimPrefix(tag, tagPrefix))
+		if err == nil && version.Compare(t, v) == 0 {
+			return tag, nil
+		}
+	}
+	return "", nil
+}
+
+// checkConstraint checks next against the range allowed by the policy
+func checkConstraint(policy BranchPolicy, branch string, next Version) error {
+	constraint, err := policy.Constraint()
+	if err != nil {
+		return err
+	}
+	if constraint != nil && !constraint.Check(next) {
+		return &PolicyViolationError{Version: next, Branch: branch, Reason: "outside of range " + constraint.String()}
+	}
+	return nil
+}
+
+// highestPrereleaseNumber returns the number of the highest prerelease tag
+// of the version, e.g. 2 when v1.3.0-next.2 exists, or 0 without such tag.
+// When rev is not empty, only the tags pointing at rev are considered.
+func highestPrereleaseNumber(ctx context.Context, repoPath, rev, tagPrefix string, next Version, pre string) (int, error) {
+	pattern := tagPrefix + next.String() + "-" + pre + ".*"
+	var tags []string
+	var err error
+	if rev != "" {
+		tags, err = git.ListTagsPointingAt(ctx, repoPath, rev, pattern)
+	} else {
+		tags, err = git.ListTags(ctx, repoPath, pattern)
+	}
+	if err != nil {
+		return 0, fmt.Errorf("listing tags: %w", err)
+	}
+
+	highest := 0
+	for _, tag := range tags {
+		v, err := version.Parse(strings.TrimPrefix(tag, tagPrefix))
+		if err != nil {
+			continue
+		}
+		n, err := strconv.Atoi(strings.TrimPrefix(v.Prerelease, pre+"."))
+		if err == nil && n > highest {
+			highest = n
+		}
+	}
+	return highest, nil
+}
+
+// latestPrerelease returns the newest prerelease tag of the branch reachable
+// from HEAD, and the number of commits since it changing path when not
+// empty. Prereleases not newer than the current release are ignored.
+func latestPrerelease(ctx context.Context, repoPath string, policy BranchPolicy, branch, tagPrefix, path string, current Version) (string, int, error) {
+	if policy.Prerelease == "" {
+		return "", 0, nil
+	}
+
+	pattern := tagPrefix + "v*-" + policy.PrereleaseFor(branch) + ".*"
+	tag, err := git.GetLatestTagMatching(ctx, repoPath, pattern)
+	if err != nil {
+		return "", 0, fmt.Errorf("getting latest prerelease tag: %w", err)
+	}
+	if tag == "" {
+		return "", 0, nil
+	}
+	v, err := version.Parse(strings.TrimPrefix(tag, tagPrefix))
+	if err != nil || version.Compare(v, current) <= 0 {
+		return "", 0, nil
+	}
+
+	n, err := git.CountCommitsSince(ctx, repoPath, tag, path)
+	if err != nil {
+		return "", 0, fmt.Errorf("counting commits since %s: %w", tag, err)
+	}
+	return tag, n, nil
+}
@@ -0,0 +1,28 @@
+package semver
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+
+	"github.com/TheScenery/sem-version/internal/cache"
+	"github.com/TheScenery/sem-version/internal/git"
+)
+
+// cacheDir returns the directory of the commit cache of the repository
+func cacheDir(ctx context.Context, repoPath string) (string, error) {
+	gitDir, err := git.GetCommonDir(ctx, repoPath)
+	if err != nil {
+		return "", fmt.Errorf("locating cache: %w", err)
+	}
+	return filepath.Join(gitDir, "sem-version"), nil
+}
+
+// ClearCache removes the commit cache of the repository, see Options.Cache
+func ClearCache(ctx context.Context, repoPath string) error {
+	dir, err := cacheDir(ctx, repoPath)
+	if err != nil {
+		return err
+	}
+	return cache.Clear(dir)
+}
@@ -0,0 +1,63 @@
+package semver
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestCompute_Cache(t *testing.T) {
+	dir := newTestRepo(t, "feat: initial", "tag:v1.0.0", "fix: bug")
+	cacheFiles := func() []string {
+		files, _ := filepath.Glob(filepath.Join(dir, ".git", "sem-version", "commits-*.json"))
+		return files
+	}
+	compute := func(cfg *Config) Result {
+		t.Helper()
+		res, err := Compute(context.Background(), dir, Options{Config: cfg, Cache: true})
+		if err != nil {
+			t.Fatalf("Compute() error = %v", err)
+		}
+		return res
+	}
+
+	cfg := &Config{Minor: []string{`^feat:`}, Patch: []string{`^fix:`}}
+	if res := compute(cfg); res.Next.String() != "v1.0.1" {
+		t.Fatalf("Compute().Next = %v, want v1.0.1", res.Next)
+	}
+	files := cacheFiles()
+	if len(files) != 1 {
+		t.Fatalf("cache files = %v, want 1", files)
+	}
+
+	// Later runs use the cached classification instead of the commit
+	data, err := os.ReadFile(files[0])
+	if err != nil {
+		t.Fatal(err)
+	}
+	tampered := strings.Replace(string(data), `"Bump":1`, `"Bump":2`, 1)
+	if err := os.WriteFile(files[0], []byte(tampered), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if res := compute(cfg); res.Next.String() != "v1.1.0" {
+		t.Errorf("Compute().Next = %v, want the cached minor bump v1.1.0", res.Next)
+	}
+
+	// New rules invalidate the cache
+	changed := &Config{Minor: []string{`^feat:`}, Patch: []string{`^(fix|chore):`}}
+	if res := compute(changed); res.Next.String() != "v1.0.1" {
+		t.Errorf("Compute().Next = %v, want v1.0.1 after a config change", res.Next)
+	}
+	if files := cacheFiles(); len(files) != 1 {
+		t.Errorf("cache files = %v, want only the current one", files)
+	}
+
+	if err := ClearCache(context.Background(), dir); err != nil {
+		t.Fatalf("ClearCache() error = %v", err)
+	}
+	if files := cacheFiles(); len(files) != 0 {
+		t.Errorf("cache files = %v after ClearCache()", files)
+	}
+}
@@ -0,0 +1,80 @@
+package semver
+
+import (
+	"bytes"
+	"os"
+	"strings"
+	"text/template"
+	"time"
+
+	"github.com/TheScenery/sem-version/pkg/version"
+)
+
+// MetadataData holds the values available to build metadata templates
+type MetadataData struct {
+	// SHA is the full hash of HEAD
+	SHA string
+	// ShortSHA is the 7 character abbreviated hash of HEAD
+	ShortSHA string
+	// Date is the build date as YYYYMMDD (UTC)
+	Date string
+	// Timestamp is the build time as YYYYMMDDHHMMSS (UTC)
+	Timestamp string
+	// Env holds the environment variables, e.g. {{.Env.BUILD_NUMBER}}
+	Env map[string]string
+}
+
+// MetadataData returns the template data of the computation at the given build time
+func (r Result) MetadataData(now time.Time) MetadataData {
+	short := r.Commit
+	if len(short) > 7 {
+		short = short[:7]
+	}
+
+	env := make(map[string]string)
+	for _, kv := range os.Environ() {
+		if k, v, ok := strings.Cut(kv, "="); ok {
+			env[k] = v
+		}
+	}
+
+	now = now.UTC()
+	return MetadataData{
+		SHA:       r.Commit,
+		ShortSHA:  short,
+		Date:      now.Format("20060102"),
+		Timestamp: now.Format("20060102150405"),
+		Env:       env,
+	}
+}
+
+// RenderMetadata executes a build metadata template such as
+// "{{.ShortSHA}}.{{.Date}}" and validates the result against the SemVer
+// build metadata grammar
+func RenderMetadata(tmpl string, data MetadataData) (string, error) {
+	t, err := template.New("metadata").Option("missingkey=zero").Parse(tmpl)
+	if err != nil {
+		return "", err
+	}
+
+	var buf bytes.Buffer
+	if err := t.Execute(&buf, data); err != nil {
+		return "", err
+	}
+
+	metadata := buf.String()
+	if err := version.ValidateMetadata(metadata); err != nil {
+		return "", err
+	}
+	return metadata, nil
+}
+
+// WithMetadata returns v with metadata appended to its build metadata
+func WithMetadata(v Version, metadata string) Version {
+	if v.Metadata == "" {
+		v.Metadata = metadata
+	} else {
+		v.Metadata += "." + metadata
+	}
+	return v
+}
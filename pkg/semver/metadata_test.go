@@ -0,0 +1,49 @@
+package semver
+
+import (
+	"testing"
+	"time"
+)
+
+func TestRenderMetadata(t *testing.T) {
+	t.Setenv("BUILD_NUMBER", "42")
+
+	res := Result{Commit: "1a2b3c4d5e6f"}
+	data := res.MetadataData(time.Date(2026, 10, 18, 9, 30, 0, 0, time.UTC))
+
+	tests := []struct {
+		tmpl    string
+		want    string
+		wantErr bool
+	}{
+		{"{{.ShortSHA}}.{{.Date}}", "1a2b3c4.20261018", false},
+		{"build.{{.Env.BUILD_NUMBER}}", "build.42", false},
+		{"{{.Timestamp}}", "20261018093000", false},
+		{"build.{{.Env.MISSING_VARIABLE}}", "", true},
+		{"{{.ShortSHA}}_{{.Date}}", "", true},
+		{"{{.Unknown}}", "", true},
+		{"{{.ShortSHA", "", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.tmpl, func(t *testing.T) {
+			got, err := RenderMetadata(tt.tmpl, data)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("RenderMetadata() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("RenderMetadata() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestWithMetadata(t *testing.T) {
+	v := Version{Major: 1, Minor: 3, Prerelease: "dev.7", Metadata: "g1a2b3c4"}
+	if got := WithMetadata(v, "build.42").String(); got != "v1.3.0-dev.7+g1a2b3c4.build.42" {
+		t.Errorf("WithMetadata() = %v", got)
+	}
+	if got := WithMetadata(Version{Major: 1}, "build.42").String(); got != "v1.0.0+build.42" {
+		t.Errorf("WithMetadata() = %v", got)
+	}
+}
@@ -0,0 +1,121 @@
+package semver
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/TheScenery/sem-version/internal/config"
+	"github.com/TheScenery/sem-version/internal/git"
+	"github.com/TheScenery/sem-version/internal/monorepo"
+	"github.com/TheScenery/sem-version/pkg/parser"
+)
+
+// Package represents a single versioned package inside a monorepo
+type Package = config.Package
+
+// PackageResult is the computed version of a single monorepo package
+type PackageResult struct {
+	Package Package
+	// Tag is the latest tag of the package, empty if none
+	Tag string
+	// HeadTag is the highest tag of the package pointing at HEAD, empty if none
+	HeadTag string
+	// PrereleaseTag is the newest prerelease tag of the package on the
+	// branch, see Result.PrereleaseTag
+	PrereleaseTag string
+	// PrereleaseCommits is the number of commits of the package since
+	// PrereleaseTag
+	PrereleaseCommits int
+	Current           Version
+	Next              Version
+	// Bump is the final bump, after dependency propagation
+	Bump BumpType
+	// PropagatedFrom lists the dependencies whose release forced a patch bump
+	PropagatedFrom []string
+	// Commits lists the commits routed to the package since Tag
+	Commits []Commit
+}
+
+// computePackages computes the version of every configured package, up to
+// repo.jobs at a time, and propagates bumps along the dependency graph
+func computePackages(ctx context.Context, repo *repository, cfg *Config, next func(string, string, Version, BumpType) (Version, error)) ([]PackageResult, error) {
+	graph, err := monorepo.NewGraph(repo.path, cfg.Packages)
+	if err != nil {
+		return nil, err
+	}
+
+	router := monorepo.NewRouter(cfg)
+	results := make([]PackageResult, len(cfg.Packages))
+	err = parallel(ctx, repo.jobs, len(cfg.Packages), func(ctx context.Context, i int) error {
+		pkg := cfg.Packages[i]
+		res, err := computePackage(ctx, repo, cfg, router, pkg)
+		if err != nil {
+			return fmt.Errorf("package %s: %w", pkg.Name, err)
+		}
+		results[i] = res
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	bumps := make(map[string]BumpType, len(results))
+	for _, res := range results {
+		bumps[res.Package.Name] = res.Bump
+	}
+
+	propagated := graph.Propagate(bumps)
+	for i := range results {
+		prop := propagated[results[i].Package.Name]
+		results[i].Bump = prop.Bump
+		results[i].PropagatedFrom = prop.Causes
+		results[i].Next, err = next(results[i].Package.GetTagPrefix(), results[i].Tag, results[i].Current, prop.Bump)
+		if err != nil {
+			return nil, fmt.Errorf("package %s: %w", results[i].Package.Name, err)
+		}
+	}
+
+	return results, nil
+}
+
+// computePackage analyzes the commits routed to a single package
+func computePackage(ctx context.Context, repo *repository, cfg *Config, router *monorepo.Router, pkg Package) (PackageResult, error) {
+	res := PackageResult{Package: pkg}
+
+	if !router.Enabled() {
+		r, err := computeRange(ctx, repo, cfg, pkg.GetTagPrefix(), pkg.Path)
+		if err != nil {
+			return res, err
+		}
+		res.Tag, res.Current, res.Bump, res.Commits = r.Tag, r.Current, r.Bump, r.Commits
+		return res, nil
+	}
+
+	// Scope routing needs every commit since the tag, not only those touching the path
+	r, err := computeRange(ctx, repo, cfg, pkg.GetTagPrefix(), "")
+	if err != nil {
+		return res, err
+	}
+	res.Tag, res.Current = r.Tag, r.Current
+
+	inPath, err := gitCall(ctx, repo, func() ([]git.Commit, error) {
+		return git.GetCommits(ctx, repo.path, logOptions(cfg, r.Tag, pkg.Path))
+	})
+	if err != nil {
+		return res, fmt.Errorf("getting commits: %w", err)
+	}
+	touched := make(map[string]bool, len(inPath))
+	for _, c := range inPath {
+		touched[c.Hash] = true
+	}
+
+	for _, c := range r.Commits {
+		scope := parser.ParseCommit(c.Subject).Scope
+		if router.Belongs(pkg.Name, scope, touched[c.Hash]) {
+			res.Commits = append(res.Commits, c)
+		}
+	}
+	res.Bump = highestBump(res.Commits)
+
+	return res, nil
+}
@@ -0,0 +1,140 @@
+package semver
+
+import (
+	"context"
+	"runtime"
+	"sync"
+
+	"github.com/TheScenery/sem-version/internal/cache"
+	"github.com/TheScenery/sem-version/internal/git"
+)
+
+// repository is the git repository of a computation, shared by the
+// computations of all packages
+type repository struct {
+	path string
+	// jobs bounds the number of concurrent computations and git processes
+	jobs int
+	// slots holds a token per running git process, see gitCall
+	slots    chan struct{}
+	messages messageCache
+	// store persists the analysis of commits across runs, nil when disabled
+	store *cache.Store[[]Commit]
+}
+
+// newRepository returns the repository at path, running at most jobs git
+// processes at a time (default: the number of CPUs)
+func newRepository(path string, jobs int) *repository {
+	if jobs <= 0 {
+		jobs = runtime.NumCPU()
+	}
+	return &repository{path: path, jobs: jobs, slots: make(chan struct{}, jobs)}
+}
+
+// gitCall runs fn, a git command, once fewer than repo.jobs git commands
+// run. Nested worker pools share the bound, so it holds for the whole
+// computation.
+func gitCall[T any](ctx context.Context, r *repository, fn func() (T, error)) (T, error) {
+	select {
+	case r.slots <- struct{}{}:
+	case <-ctx.Done():
+		var zero T
+		return zero, ctx.Err()
+	}
+	defer func() { <-r.slots }()
+	return fn()
+}
+
+// cached returns the persisted analysis of a commit
+func (r *repository) cached(hash string) ([]Commit, bool) {
+	if r.store == nil {
+		return nil, false
+	}
+	return r.store.Get(hash)
+}
+
+// remember persists the analysis of a commit
+func (r *repository) remember(hash string, commits []Commit) {
+	if r.store != nil {
+		r.store.Put(hash, commits)
+	}
+}
+
+// messageCache holds the full commit messages read, so each commit is read
+// once even when several packages analyze it concurrently
+type messageCache struct {
+	mu      sync.Mutex
+	entries map[string]*messageEntry
+}
+
+type messageEntry struct {
+	once    sync.Once
+	message string
+	err     error
+}
+
+// message returns the full message of the commit
+func (r *repository) message(ctx context.Context, hash string) (string, error) {
+	r.messages.mu.Lock()
+	if r.messages.entries == nil {
+		r.messages.entries = make(map[string]*messageEntry)
+	}
+	e, ok := r.messages.entries[hash]
+	if !ok {
+		e = &messageEntry{}
+		r.messages.entries[hash] = e
+	}
+	r.messages.mu.Unlock()
+
+	e.once.Do(func() {
+		e.message, e.err = gitCall(ctx, r, func() (string, error) {
+			return git.GetFullCommitMessage(ctx, r.path, hash)
+		})
+	})
+	return e.message, e.err
+}
+
+// parallel calls fn for each index in [0, n) from at most jobs goroutines
+// and returns the first error, after which the context passed to fn is
+// cancelled. Callers store results by index to keep a deterministic order.
+func parallel(ctx context.Context, jobs, n int, fn func(ctx context.Context, i int) error) error {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	var (
+		wg       sync.WaitGroup
+		once     sync.Once
+		firstErr error
+	)
+	indexes := make(chan int)
+	for range max(1, min(jobs, n)) {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range indexes {
+				if err := fn(ctx, i); err != nil {
+					once.Do(func() {
+						firstErr = err
+						cancel()
+					})
+				}
+			}
+		}()
+	}
+
+feed:
+	for i := range n {
+		select {
+		case indexes <- i:
+		case <-ctx.Done():
+			break feed
+		}
+	}
+	close(indexes)
+	wg.Wait()
+
+	if firstErr != nil {
+		return firstErr
+	}
+	return ctx.Err()
+}
@@ -0,0 +1,176 @@
+package semver
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"testing"
+)
+
+func TestParallel(t *testing.T) {
+	for _, jobs := range []int{0, 1, 4, 100} {
+		t.Run(fmt.Sprint(jobs), func(t *testing.T) {
+			var running, peak atomic.Int32
+			results := make([]int, 50)
+
+			err := parallel(context.Background(), jobs, len(results), func(ctx context.Context, i int) error {
+				n := running.Add(1)
+				defer running.Add(-1)
+				for {
+					p := peak.Load()
+					if n <= p || peak.CompareAndSwap(p, n) {
+						break
+					}
+				}
+				results[i] = i * i
+				return nil
+			})
+			if err != nil {
+				t.Fatalf("parallel() error = %v", err)
+			}
+
+			for i, r := range results {
+				if r != i*i {
+					t.Fatalf("results[%d] = %d, want %d", i, r, i*i)
+				}
+			}
+			if limit := int32(max(1, jobs)); peak.Load() > limit {
+				t.Errorf("peak concurrency = %d, want at most %d", peak.Load(), limit)
+			}
+		})
+	}
+}
+
+func TestParallel_Error(t *testing.T) {
+	errBoom := errors.New("boom")
+	var calls atomic.Int32
+
+	err := parallel(context.Background(), 2, 1000, func(ctx context.Context, i int) error {
+		calls.Add(1)
+		if i == 3 {
+			return errBoom
+		}
+		return ctx.Err()
+	})
+	if !errors.Is(err, errBoom) {
+		t.Errorf("parallel() error = %v, want %v", err, errBoom)
+	}
+	if calls.Load() == 1000 {
+		t.Error("parallel() kept going after an error")
+	}
+}
+
+// newPackagesRepo creates a monorepo with packages a, b and c, and returns
+// it with its config
+func newPackagesRepo(t *testing.T) (string, *Config) {
+	t.Helper()
+	dir := newTestRepo(t, "chore: initial", "tag:a/v1.0.0", "tag:b/v1.0.0", "tag:c/v1.0.0")
+	commits := []struct{ pkg, message string }{
+		{"a", "feat: a"},
+		{"b", "fix: b"},
+		{"c", "feat!: c"},
+		{"a", "fix: a again"},
+	}
+	for i, c := range commits {
+		path := filepath.Join(dir, c.pkg, fmt.Sprintf("file%d.txt", i))
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(c.message), 0644); err != nil {
+			t.Fatal(err)
+		}
+		runGit(t, dir, "add", "-A")
+		runGit(t, dir, "commit", "-q", "-m", c.message)
+	}
+
+	cfg := &Config{
+		Major: []string{`^.+!:`},
+		Minor: []string{`^feat:`},
+		Patch: []string{`^fix:`},
+		Packages: []Package{
+			{Name: "a", Path: "a"},
+			{Name: "b", Path: "b"},
+			{Name: "c", Path: "c"},
+		},
+	}
+	return dir, cfg
+}
+
+func TestCompute_PackagesJobs(t *testing.T) {
+	dir, cfg := newPackagesRepo(t)
+	want := []string{"a v1.1.0 2", "b v1.0.1 1", "c v2.0.0 1"}
+
+	for _, jobs := range []int{1, 3} {
+		t.Run(fmt.Sprint(jobs), func(t *testing.T) {
+			res, err := Compute(context.Background(), dir, Options{Config: cfg, Jobs: jobs})
+			if err != nil {
+				t.Fatalf("Compute() error = %v", err)
+			}
+			if len(res.Packages) != len(want) {
+				t.Fatalf("Compute().Packages = %+v", res.Packages)
+			}
+			for i, p := range res.Packages {
+				if got := fmt.Sprintf("%s %s %d", p.Package.Name, p.Next, len(p.Commits)); got != want[i] {
+					t.Errorf("Packages[%d] = %q, want %q", i, got, want[i])
+				}
+			}
+		})
+	}
+}
+
+func TestCompute_GitProcessesBound(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("needs a shell script as git")
+	}
+	dir, cfg := newPackagesRepo(t)
+
+	realGit, err := exec.LookPath("git")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// The fake git records how many git processes are running, each one
+	// lasting long enough to overlap with the others
+	bin, state := t.TempDir(), t.TempDir()
+	script := fmt.Sprintf(`#!/bin/sh
+mkdir "%[1]s/run.$$"
+ls -d "%[1]s"/run.* | wc -l >> "%[1]s/counts"
+sleep 0.05
+"%[2]s" "$@"
+status=$?
+rmdir "%[1]s/run.$$"
+exit $status
+`, state, realGit)
+	if err := os.WriteFile(filepath.Join(bin, "git"), []byte(script), 0755); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("PATH", bin+string(os.PathListSeparator)+os.Getenv("PATH"))
+
+	const jobs = 2
+	if _, err := Compute(context.Background(), dir, Options{Config: cfg, Jobs: jobs}); err != nil {
+		t.Fatalf("Compute() error = %v", err)
+	}
+
+	data, err := os.ReadFile(filepath.Join(state, "counts"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	peak := 0
+	for _, line := range strings.Fields(string(data)) {
+		n, err := strconv.Atoi(line)
+		if err != nil {
+			t.Fatalf("counts: %v", err)
+		}
+		peak = max(peak, n)
+	}
+	if peak > jobs {
+		t.Errorf("peak concurrent git processes = %d, want at most %d", peak, jobs)
+	}
+}
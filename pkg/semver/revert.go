@@ -0,0 +1,43 @@
+package semver
+
+import (
+	"strings"
+
+	"github.com/TheScenery/sem-version/pkg/parser"
+)
+
+// Cancelled reports whether the commit is paired with a revert within the
+// analyzed range, either as the revert or as the reverted commit.
+// Cancelled commits trigger no bump and are left out of changelogs.
+func (c Commit) Cancelled() bool {
+	return c.Reverts != "" || c.RevertedBy != ""
+}
+
+// pairReverts pairs the revert commits with their targets in commits,
+// oldest first. Reverts are paired newest first, so reverting a revert
+// cancels the revert and keeps the original commit.
+func pairReverts(commits []Commit) {
+	for i := len(commits) - 1; i >= 0; i-- {
+		revert := &commits[i]
+		if revert.Cancelled() {
+			continue
+		}
+		target := parser.ParseRevert(revert.Message)
+		if target == "" {
+			continue
+		}
+
+		for j := i - 1; j >= 0; j-- {
+			if commits[j].Cancelled() || !strings.HasPrefix(commits[j].Hash, target) {
+				continue
+			}
+			// Reverting a squash commit cancels all its virtual commits
+			hash := commits[j].Hash
+			revert.Reverts = hash
+			for k := j; k >= 0 && commits[k].Hash == hash; k-- {
+				commits[k].RevertedBy = revert.Hash
+			}
+			break
+		}
+	}
+}
@@ -0,0 +1,41 @@
+package semver
+
+import (
+	"testing"
+)
+
+func TestPairReverts(t *testing.T) {
+	commits := []Commit{
+		{Hash: "aaaaaaaa", Message: "feat: login", Bump: BumpMinor},
+		{Hash: "bbbbbbbb", Message: "fix: typo", Bump: BumpPatch},
+		{Hash: "cccccccc", Message: "Revert \"feat: login\"\n\nThis reverts commit aaaaaaaa."},
+		{Hash: "dddddddd", Message: "feat!: drop v1 API", Bump: BumpMajor},
+		{Hash: "eeeeeeee", Message: "Revert \"feat!: drop v1 API\"\n\nThis reverts commit ddddddd."},
+		{Hash: "ffffffff", Message: "Revert \"Revert \"feat!: drop v1 API\"\"\n\nThis reverts commit eeeeeeee."},
+		{Hash: "11111111", Message: "Revert \"feat: old\"\n\nThis reverts commit 9999999."},
+		{Hash: "22222222", Message: "feat: a", Bump: BumpMinor, Squashed: true},
+		{Hash: "22222222", Message: "fix: b", Bump: BumpPatch, Squashed: true},
+		{Hash: "33333333", Message: "Revert \"Login (#3)\"\n\nThis reverts commit 2222222."},
+	}
+
+	pairReverts(commits)
+
+	want := map[string][2]string{
+		"aaaaaaaa": {"", "cccccccc"},
+		"cccccccc": {"aaaaaaaa", ""},
+		"eeeeeeee": {"", "ffffffff"},
+		"ffffffff": {"eeeeeeee", ""},
+		"22222222": {"", "33333333"},
+		"33333333": {"22222222", ""},
+	}
+	for _, c := range commits {
+		w := want[c.Hash]
+		if c.Reverts != w[0] || c.RevertedBy != w[1] {
+			t.Errorf("%s: Reverts = %q, RevertedBy = %q, want %q, %q", c.Hash, c.Reverts, c.RevertedBy, w[0], w[1])
+		}
+	}
+
+	if got := highestBump(commits); got != BumpMajor {
+		t.Errorf("highestBump() = %v, want %v", got, BumpMajor)
+	}
+}
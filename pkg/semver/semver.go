@@ -0,0 +1,622 @@
+// Package semver computes semantic versions from Git commit history.
+//
+// It is the library behind the sem-version CLI:
+//
+//	res, err := semver.Compute(ctx, ".", semver.Options{})
+//	if err != nil {
+//		return err
+//	}
+//	fmt.Println(res.Next)
+//
+// Version, BumpType and Constraint are the types of package version, and
+// ParsedCommit and CommitType those of package parser.
+package semver
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/TheScenery/sem-version/internal/cache"
+	"github.com/TheScenery/sem-version/internal/config"
+	"github.com/TheScenery/sem-version/internal/git"
+	"github.com/TheScenery/sem-version/internal/pathfilter"
+	"github.com/TheScenery/sem-version/pkg/parser"
+	"github.com/TheScenery/sem-version/pkg/version"
+)
+
+// Version represents a semantic version
+type Version = version.Version
+
+// BumpType represents the type of version bump
+type BumpType = version.BumpType
+
+// Bump types, from lowest to highest
+const (
+	BumpNone  = version.BumpNone
+	BumpPatch = version.BumpPatchType
+	BumpMinor = version.BumpMinorType
+	BumpMajor = version.BumpMajorType
+)
+
+// Config holds the commit classification rules and monorepo settings
+type Config = config.Config
+
+// ParsedCommit represents a parsed conventional commit
+type ParsedCommit = parser.ParsedCommit
+
+// CommitType represents the type of a conventional commit
+type CommitType = parser.CommitType
+
+// Constraint is a version range such as ">=1.2.0 <2.0.0", "~1.4" or "1.x"
+type Constraint = version.Constraint
+
+// ParseVersion parses a version string such as "v1.2.3-rc.1+build.5"
+func ParseVersion(v string) (Version, error) {
+	return version.Parse(v)
+}
+
+// ParseConstraint parses a version constraint, see Constraint
+func ParseConstraint(s string) (Constraint, error) {
+	return version.ParseConstraint(s)
+}
+
+// ParseCommit parses a commit message according to the Conventional Commits spec
+func ParseCommit(message string) ParsedCommit {
+	return parser.ParseCommit(message)
+}
+
+// LoadConfig loads configuration from a file
+func LoadConfig(path string) (*Config, error) {
+	return config.Load(path)
+}
+
+// LoadDefaultConfig loads .sem-version.yaml or .sem-version.yml from dir,
+// falling back to the default Conventional Commits rules
+func LoadDefaultConfig(dir string) (*Config, error) {
+	return config.LoadDefault(dir)
+}
+
+// Options configures a version computation
+type Options struct {
+	// Config holds the rules to apply. If nil, the config is loaded from
+	// ConfigPath or auto-detected in the repository.
+	Config *Config
+	// ConfigPath is the path of the config file to load when Config is nil
+	ConfigPath string
+	// Now is the release time used by calendar versioning (default: time.Now)
+	Now time.Time
+	// Branch selects the branch release policy (default: the checked out branch)
+	Branch string
+	// Deepen is the number of commits fetched at a time from the remote of
+	// a shallow clone until a version tag is reachable. When 0, Compute
+	// fails with an error wrapping ErrShallow instead.
+	Deepen int
+	// Jobs bounds the number of packages computed concurrently and of
+	// concurrent git processes (default: the number of CPUs)
+	Jobs int
+	// Cache stores the analysis of each commit under .git/sem-version/ and
+	// reuses it in later computations with the same classification rules
+	Cache bool
+}
+
+// Errors returned by Compute, to be tested with errors.Is
+var (
+	// ErrGitNotFound reports that the git executable is not in PATH
+	ErrGitNotFound = git.ErrGitNotFound
+	// ErrNotARepository reports that the path is not inside a git repository
+	ErrNotARepository = git.ErrNotARepository
+	// ErrNoCommits reports a repository whose current branch has no commits
+	ErrNoCommits = git.ErrNoCommits
+	// ErrShallow reports a shallow clone in which no version tag is
+	// reachable, so the latest version cannot be known, see Options.Deepen
+	ErrShallow = git.ErrShallow
+)
+
+// RepositoryRoot returns the root of the working tree containing path, in
+// which the config file is auto-detected and bump files are resolved
+func RepositoryRoot(ctx context.Context, path string) (string, error) {
+	return git.GetTopLevel(ctx, path)
+}
+
+// Commit is a commit analyzed during a computation
+type Commit struct {
+	Hash    string
+	Subject string
+	Message string
+	// Bump is the bump triggered by this commit alone
+	Bump BumpType
+	// Merge reports whether the commit is a merge commit
+	Merge bool
+	// Reverts is the hash of the commit reverted by this commit within the
+	// analyzed range, empty otherwise
+	Reverts string
+	// RevertedBy is the hash of the commit reverting this commit within the
+	// analyzed range, empty otherwise
+	RevertedBy string
+	// Squashed reports whether the commit is a virtual commit listed in the
+	// body of a squash commit, see Config.SquashCommits
+	Squashed bool
+	// Excluded reports whether the commit only changes files left out by the
+	// path filters, see Config.IncludePaths and Config.ExcludePaths.
+	// Excluded commits trigger no bump and are left out of changelogs.
+	Excluded bool
+}
+
+// Result is the outcome of a version computation
+type Result struct {
+	// Tag is the latest version tag, empty if the repository has none
+	Tag string
+	// HeadTag is the highest version tag pointing at HEAD, empty if none.
+	// With branch policies, it can be a prerelease tag newer than Tag.
+	HeadTag string
+	// PrereleaseTag is the newest prerelease tag of the branch reachable
+	// from HEAD and newer than Tag, empty without a prerelease branch policy
+	PrereleaseTag string
+	// PrereleaseCommits is the number of commits since PrereleaseTag
+	PrereleaseCommits int
+	// Current is the version of Tag, v0.0.0 without tag
+	Current Version
+	// Next is the computed next version
+	Next Version
+	// Bump is the highest bump among the analyzed commits
+	Bump BumpType
+	// Branch is the branch whose release policy was applied, if any
+	Branch string
+	// Policy is the applied branch release policy, nil without branch policies
+	Policy *BranchPolicy
+	// Commits lists the commits since Tag, oldest first
+	Commits []Commit
+	// Commit is the hash of HEAD, empty in a repository without commits
+	Commit string
+	// Dirty reports whether the worktree has uncommitted changes
+	Dirty bool
+	// Packages holds per-package results when the config declares packages
+	Packages []PackageResult
+}
+
+// Compute determines the current and next version of the repository at repoPath
+func Compute(ctx context.Context, repoPath string, opts Options) (Result, error) {
+	cfg, err := resolveConfig(repoPath, opts)
+	if err != nil {
+		return Result{}, err
+	}
+
+	scheme, err := cfg.VersionScheme()
+	if err != nil {
+		return Result{}, err
+	}
+	now := opts.Now
+	if now.IsZero() {
+		now = time.Now()
+	}
+
+	policy, branch, err := resolveBranchPolicy(ctx, repoPath, cfg, opts.Branch)
+	if err != nil {
+		return Result{}, err
+	}
+
+	next := func(tagPrefix, tag string, current Version, bump BumpType) (Version, error) {
+		// Only branch policies release prereleases, so a prerelease or build
+		// metadata left over from the latest tag is dropped, e.g. v1.0.0
+		// after a v1.0.0-rc.1 tag
+		v := scheme.Next(current, tag != "", bump, now)
+		v.Prerelease, v.Metadata = "", ""
+		if policy == nil {
+			return v, nil
+		}
+
+		v, err := applyBranchPolicy(ctx, repoPath, *policy, branch, tagPrefix, tag, current, v)
+		var violation *PolicyViolationError
+		if errors.As(err, &violation) && policy.OnViolation == config.ViolationPatch && bump > BumpPatch {
+			v = scheme.Next(current, tag != "", BumpPatch, now)
+			v.Prerelease, v.Metadata = "", ""
+			return applyBranchPolicy(ctx, repoPath, *policy, branch, tagPrefix, tag, current, v)
+		}
+		return v, err
+	}
+
+	repo := newRepository(repoPath, opts.Jobs)
+	if opts.Cache {
+		dir, err := cacheDir(ctx, repoPath)
+		if err != nil {
+			return Result{}, err
+		}
+		repo.store = cache.Open[[]Commit](dir, cfg.ClassificationKey())
+	}
+
+	res, err := computeVersions(ctx, repo, cfg, next)
+	for errors.Is(err, ErrShallow) && opts.Deepen > 0 {
+		if err := ctx.Err(); err != nil {
+			return Result{}, err
+		}
+		if err := git.Deepen(ctx, repoPath, opts.Deepen); err != nil {
+			return Result{}, fmt.Errorf("deepening shallow clone: %w", err)
+		}
+		res, err = computeVersions(ctx, repo, cfg, next)
+	}
+	if err != nil {
+		return Result{}, err
+	}
+	res.Branch, res.Policy = branch, policy
+
+	// The cache only saves work, failing to write it must not fail the run
+	if repo.store != nil {
+		_ = repo.store.Save()
+	}
+
+	// A repository without commits has no HEAD yet
+	res.Commit, _ = git.GetHeadCommit(ctx, repoPath)
+	if res.Commit != "" {
+		if res.HeadTag, err = headTag(ctx, repoPath, ""); err != nil {
+			return Result{}, err
+		}
+		for i := range res.Packages {
+			if res.Packages[i].HeadTag, err = headTag(ctx, repoPath, res.Packages[i].Package.GetTagPrefix()); err != nil {
+				return Result{}, err
+			}
+		}
+		if policy != nil {
+			if err := res.findPrereleases(ctx, repoPath, *policy, branch); err != nil {
+				return Result{}, err
+			}
+		}
+	}
+	res.Dirty, err = git.IsDirty(ctx, repoPath)
+	if err != nil {
+		return Result{}, fmt.Errorf("checking worktree: %w", err)
+	}
+
+	return res, nil
+}
+
+// computeVersions computes the version of the repository or of each package
+func computeVersions(ctx context.Context, repo *repository, cfg *Config, next func(string, string, Version, BumpType) (Version, error)) (Result, error) {
+	if len(cfg.Packages) > 0 {
+		packages, err := computePackages(ctx, repo, cfg, next)
+		return Result{Packages: packages}, err
+	}
+
+	res, err := computeRange(ctx, repo, cfg, "", "")
+	if err != nil {
+		return Result{}, err
+	}
+	res.Next, err = next("", res.Tag, res.Current, res.Bump)
+	return res, err
+}
+
+// resolveConfig returns the config to use for a computation
+func resolveConfig(repoPath string, opts Options) (*Config, error) {
+	if opts.Config != nil {
+		return opts.Config, opts.Config.Compile()
+	}
+	if opts.ConfigPath != "" {
+		cfg, err := config.Load(opts.ConfigPath)
+		if err != nil {
+			return nil, fmt.Errorf("loading config %s: %w", opts.ConfigPath, err)
+		}
+		return cfg, nil
+	}
+	cfg, err := config.LoadDefault(repoPath)
+	if err != nil {
+		return nil, fmt.Errorf("loading config: %w", err)
+	}
+	return cfg, nil
+}
+
+// computeRange finds the latest tag with the given prefix and analyzes the
+// commits since, restricted to path when not empty. With branch policies,
+// prerelease tags are ignored so versions are computed from the latest release.
+func computeRange(ctx context.Context, repo *repository, cfg *Config, tagPrefix, path string) (Result, error) {
+	var res Result
+
+	latestTag := git.GetLatestTagWithPrefix
+	if len(cfg.Branches) > 0 {
+		latestTag = git.GetLatestReleaseTagWithPrefix
+	}
+
+	tag, err := gitCall(ctx, repo, func() (string, error) {
+		return latestTag(ctx, repo.path, tagPrefix)
+	})
+	if err != nil {
+		return res, fmt.Errorf("getting latest tag: %w", err)
+	}
+	res.Tag = tag
+
+	// Without tag, a shallow clone may just be missing the history holding it
+	if tag == "" {
+		shallow, err := gitCall(ctx, repo, func() (bool, error) {
+			return git.IsShallow(ctx, repo.path)
+		})
+		if err != nil {
+			return res, fmt.Errorf("checking for shallow clone: %w", err)
+		}
+		if shallow {
+			return res, fmt.Errorf("no %sv* tag is reachable in the %w: fetch the full history (e.g. fetch-depth: 0 with actions/checkout) or deepen it with --deepen", tagPrefix, ErrShallow)
+		}
+	}
+
+	if tag != "" {
+		res.Current, err = version.Parse(tag[len(tagPrefix):])
+		if err != nil {
+			return res, fmt.Errorf("parsing version %s: %w", tag, err)
+		}
+	}
+
+	filter, err := cfg.PathFilter()
+	if err != nil {
+		return res, err
+	}
+
+	opts := logOptions(cfg, tag, path)
+	opts.Files = filter.Enabled()
+	commits, err := gitCall(ctx, repo, func() ([]git.Commit, error) {
+		return git.GetCommits(ctx, repo.path, opts)
+	})
+	if err != nil {
+		return res, fmt.Errorf("getting commits: %w", err)
+	}
+
+	res.Commits, err = analyzeCommits(ctx, repo, cfg, filter, commits)
+	if err != nil {
+		return res, err
+	}
+	pairReverts(res.Commits)
+	res.Bump = highestBump(res.Commits)
+
+	return res, nil
+}
+
+// logOptions returns the git log options selecting the commits since tag
+// that touch path, following the history mode of the config
+func logOptions(cfg *Config, tag, path string) git.LogOptions {
+	return git.LogOptions{
+		Since:       tag,
+		Path:        path,
+		FirstParent: cfg.History == config.HistoryFirstParent,
+	}
+}
+
+// analyzeCommits classifies each commit using the config patterns, and
+// marks the commits whose changed files are all filtered out
+func analyzeCommits(ctx context.Context, repo *repository, cfg *Config, filter *pathfilter.Filter, commits []git.Commit) ([]Commit, error) {
+	classified := make([][]Commit, len(commits))
+	err := parallel(ctx, repo.jobs, len(commits), func(ctx context.Context, i int) error {
+		c := commits[i]
+		if cached, ok := repo.cached(c.Hash); ok {
+			classified[i] = cached
+			return nil
+		}
+
+		// Get full commit message for better matching
+		message, err := repo.message(ctx, c.Hash)
+		if err != nil {
+			if ctx.Err() != nil {
+				return ctx.Err()
+			}
+			classified[i] = classifyCommit(cfg, c, c.Message)
+			return nil
+		}
+		classified[i] = classifyCommit(cfg, c, message)
+		repo.remember(c.Hash, classified[i])
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	analyzed := make([]Commit, 0, len(commits))
+	for i, c := range commits {
+		excluded := filter.Enabled() && !filter.MatchAny(c.Files)
+		for _, a := range classified[i] {
+			a.Excluded = excluded
+			analyzed = append(analyzed, a)
+		}
+	}
+	return analyzed, nil
+}
+
+// classifyCommit classifies a commit from its full message, into several
+// virtual commits for an expanded squash commit
+func classifyCommit(cfg *Config, c git.Commit, message string) []Commit {
+	subject := c.Message
+	if c.Merge && cfg.MergeCommits == config.MergePullRequest {
+		if pr := pullRequestMessage(message); pr != "" {
+			message = pr
+			subject, _, _ = strings.Cut(pr, "\n")
+		}
+	}
+
+	commit := Commit{
+		Hash:    c.Hash,
+		Subject: subject,
+		Message: message,
+		Bump:    cfg.Classify(message),
+		Merge:   c.Merge,
+	}
+	if cfg.SquashCommits == config.SquashExpand {
+		if parts := parser.SplitSquash(message); parts != nil {
+			return squashedCommits(cfg, commit, parts)
+		}
+	}
+	return []Commit{commit}
+}
+
+// squashedCommits returns the virtual commits of the messages listed in the
+// body of a squash commit. The title, usually the PR title, is kept as a
+// virtual commit when it calls for a higher bump than the listed commits,
+// e.g. "feat!: drop v1 API" over "* fix: a".
+func squashedCommits(cfg *Config, squash Commit, messages []string) []Commit {
+	commits := make([]Commit, 0, len(messages)+1)
+	bump := BumpNone
+	for _, message := range messages {
+		c := squash
+		c.Subject, _, _ = strings.Cut(message, "\n")
+		c.Message = message
+		c.Bump = cfg.Classify(message)
+		c.Squashed = true
+		commits = append(commits, c)
+		bump = max(bump, c.Bump)
+	}
+
+	title := squash
+	title.Message = squash.Subject
+	title.Bump = cfg.Classify(squash.Subject)
+	title.Squashed = true
+	if title.Bump > bump {
+		commits = append([]Commit{title}, commits...)
+	}
+	return commits
+}
+
+// pullRequestMessage returns the PR title and body of a merge commit
+// message such as "Merge pull request #12 from org/branch\n\nfeat: title",
+// or "" when the message has no body
+func pullRequestMessage(message string) string {
+	_, body, _ := strings.Cut(message, "\n")
+	return strings.TrimSpace(body)
+}
+
+// highestBump returns the highest bump among the commits neither cancelled
+// by a revert nor excluded by the path filters
+func highestBump(commits []Commit) BumpType {
+	bump := BumpNone
+	for _, c := range commits {
+		if !c.Cancelled() && !c.Excluded && c.Bump > bump {
+			bump = c.Bump
+		}
+	}
+	return bump
+}
+
+// CountCommits returns the number of git commits among the analyzed
+// commits, counting the virtual commits of a squash commit once
+func CountCommits(commits []Commit) int {
+	seen := make(map[string]bool, len(commits))
+	for _, c := range commits {
+		seen[c.Hash] = true
+	}
+	return len(seen)
+}
+
+// headTag returns the highest version tag with the prefix pointing at HEAD
+func headTag(ctx context.Context, repoPath, tagPrefix string) (string, error) {
+	tags, err := git.ListTagsPointingAt(ctx, repoPath, "HEAD", tagPrefix+"v*")
+	if err != nil {
+		return "", fmt.Errorf("listing tags: %w", err)
+	}
+
+	var highest string
+	var highestVersion Version
+	for _, tag := range tags {
+		v, err := version.Parse(strings.TrimPrefix(tag, tagPrefix))
+		if err != nil {
+			continue
+		}
+		if highest == "" || version.Compare(v, highestVersion) > 0 {
+			highest, highestVersion = tag, v
+		}
+	}
+	return highest, nil
+}
+
+// findPrereleases fills in the latest prerelease of the branch, the base of
+// development versions, for the repository or each package
+func (r *Result) findPrereleases(ctx context.Context, repoPath string, policy BranchPolicy, branch string) error {
+	var err error
+	if len(r.Packages) == 0 {
+		r.PrereleaseTag, r.PrereleaseCommits, err = latestPrerelease(ctx, repoPath, policy, branch, "", "", r.Current)
+		return err
+	}
+	for i := range r.Packages {
+		p := &r.Packages[i]
+		p.PrereleaseTag, p.PrereleaseCommits, err = latestPrerelease(ctx, repoPath, policy, branch, p.Package.GetTagPrefix(), p.Package.Path, p.Current)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Describe returns the version of the checkout: the version of HeadTag when
+// HEAD is tagged, otherwise a development version of the next release such as
+// v1.3.0-dev.7+g1a2b3c4, or of the latest prerelease of the branch such as
+// v1.3.0-next.1.dev.2+g1a2b3c4. The build metadata gets a "dirty" marker when
+// the worktree has uncommitted changes.
+func (r Result) Describe() Version {
+	base, commits := devBase(r.PrereleaseTag, "", r.PrereleaseCommits, r.Tag, r.Current, r.Next, CountCommits(r.Commits))
+	return describe(r.HeadTag, "", base, commits, r.Commit, r.Dirty)
+}
+
+// DescribePackage returns the version of the checkout for a package result
+// of a monorepo computation, see Describe. A package without commits since
+// its tag keeps the version of the tag.
+func (r Result) DescribePackage(p PackageResult) Version {
+	headTag, tagPrefix := p.HeadTag, p.Package.GetTagPrefix()
+	if headTag == "" && p.Tag != "" && len(p.Commits) == 0 {
+		// Nothing touched the package since its release, which is still
+		// its version
+		headTag = p.Tag
+	}
+	base, commits := devBase(p.PrereleaseTag, tagPrefix, p.PrereleaseCommits, p.Tag, p.Current, p.Next, CountCommits(p.Commits))
+	return describe(headTag, tagPrefix, base, commits, r.Commit, r.Dirty)
+}
+
+// devBase returns the version a development version builds on, which it must
+// sort above, and the number of commits since it: the latest prerelease of
+// the branch when there is one, or the prerelease of the latest tag when the
+// next version releases it, otherwise the next version
+func devBase(prereleaseTag, tagPrefix string, prereleaseCommits int, tag string, current, next Version, commits int) (Version, int) {
+	if v, err := version.Parse(strings.TrimPrefix(prereleaseTag, tagPrefix)); prereleaseTag != "" && err == nil {
+		v.Metadata = ""
+		return v, prereleaseCommits
+	}
+
+	v := next
+	v.Prerelease, v.Metadata = "", ""
+	release := current
+	release.Prerelease, release.Metadata = "", ""
+	switch {
+	case tag == "" || v != release:
+	case current.Prerelease != "":
+		current.Metadata = ""
+		return current, commits
+	default:
+		// Commits without a bump still come after the tag
+		v = current.BumpPatch()
+	}
+	return v, commits
+}
+
+// describe returns the version of headTag, or a development version built on
+// base with the number of commits since it
+func describe(headTag, tagPrefix string, base Version, commits int, hash string, dirty bool) Version {
+	var v Version
+	var metadata []string
+
+	headVersion, err := version.Parse(strings.TrimPrefix(headTag, tagPrefix))
+	switch {
+	case headTag != "" && err == nil:
+		v = headVersion
+	default:
+		v = base
+		dev := fmt.Sprintf("dev.%d", commits)
+		if v.Prerelease != "" {
+			dev = v.Prerelease + "." + dev
+		}
+		v.Prerelease = dev
+		if len(hash) > 7 {
+			metadata = append(metadata, "g"+hash[:7])
+		}
+	}
+
+	if dirty {
+		metadata = append(metadata, "dirty")
+	}
+	if len(metadata) > 0 {
+		v.Metadata = strings.Join(metadata, ".")
+	}
+	return v
+}
@@ -0,0 +1,689 @@
+package semver
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/TheScenery/sem-version/internal/git"
+)
+
+// newTestRepo creates a git repository with the given commits, tagging the
+// commit after which a tag is listed
+func newTestRepo(t *testing.T, steps ...string) string {
+	t.Helper()
+	dir := t.TempDir()
+
+	run := func(args ...string) {
+		t.Helper()
+		runGit(t, dir, args...)
+	}
+
+	run("init", "-q")
+	run("config", "user.email", "test@example.com")
+	run("config", "user.name", "test")
+	run("config", "commit.gpgsign", "false")
+
+	for _, step := range steps {
+		if len(step) > 4 && step[:4] == "tag:" {
+			run("tag", step[4:])
+			continue
+		}
+		run("commit", "-q", "--allow-empty", "-m", step)
+	}
+	return dir
+}
+
+// runGit runs a git command in dir, failing the test on error
+func runGit(t *testing.T, dir string, args ...string) {
+	t.Helper()
+	cmd := exec.Command("git", args...)
+	cmd.Dir = dir
+	if out, err := cmd.CombinedOutput(); err != nil {
+		t.Fatalf("git %v: %v\n%s", args, err, out)
+	}
+}
+
+func TestCompute(t *testing.T) {
+	tests := []struct {
+		name    string
+		steps   []string
+		wantTag string
+		want    string
+		bump    BumpType
+	}{
+		{
+			name:  "no tags",
+			steps: []string{"feat: initial"},
+			want:  "v0.1.0",
+			bump:  BumpMinor,
+		},
+		{
+			name:    "fix after tag",
+			steps:   []string{"feat: initial", "tag:v0.1.0", "fix: bug"},
+			wantTag: "v0.1.0",
+			want:    "v0.1.1",
+			bump:    BumpPatch,
+		},
+		{
+			name:    "breaking change wins",
+			steps:   []string{"feat: initial", "tag:v1.2.3", "fix: bug", "feat!: redesign", "feat: more"},
+			wantTag: "v1.2.3",
+			want:    "v2.0.0",
+			bump:    BumpMajor,
+		},
+		{
+			name:    "no bump commits",
+			steps:   []string{"feat: initial", "tag:v1.0.0", "docs: readme"},
+			wantTag: "v1.0.0",
+			want:    "v1.0.0",
+			bump:    BumpNone,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := newTestRepo(t, tt.steps...)
+
+			res, err := Compute(context.Background(), dir, Options{})
+			if err != nil {
+				t.Fatalf("Compute() error = %v", err)
+			}
+			if res.Tag != tt.wantTag {
+				t.Errorf("Compute().Tag = %v, want %v", res.Tag, tt.wantTag)
+			}
+			if got := res.Next.String(); got != tt.want {
+				t.Errorf("Compute().Next = %v, want %v", got, tt.want)
+			}
+			if res.Bump != tt.bump {
+				t.Errorf("Compute().Bump = %v, want %v", res.Bump, tt.bump)
+			}
+			if res.Commit == "" {
+				t.Error("Compute().Commit is empty")
+			}
+		})
+	}
+}
+
+func TestCompute_Cancelled(t *testing.T) {
+	dir := newTestRepo(t, "feat: initial")
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	if _, err := Compute(ctx, dir, Options{}); err == nil {
+		t.Error("Expected error for cancelled context")
+	}
+}
+
+// testCommits returns n commits with distinct hashes
+func testCommits(n int) []Commit {
+	commits := make([]Commit, n)
+	for i := range commits {
+		commits[i].Hash = fmt.Sprintf("%040x", i)
+	}
+	return commits
+}
+
+func TestResult_Describe(t *testing.T) {
+	v1 := Version{Major: 1, Minor: 2, Patch: 0}
+	hash := "1a2b3c4d5e6f7a8b9c0d"
+
+	tests := []struct {
+		name string
+		res  Result
+		want string
+	}{
+		{
+			name: "tagged HEAD",
+			res:  Result{Tag: "v1.2.0", HeadTag: "v1.2.0", Current: v1, Next: v1, Commit: hash},
+			want: "v1.2.0",
+		},
+		{
+			name: "tagged HEAD with dirty worktree",
+			res:  Result{Tag: "v1.2.0", HeadTag: "v1.2.0", Current: v1, Next: v1, Commit: hash, Dirty: true},
+			want: "v1.2.0+dirty",
+		},
+		{
+			name: "commits since tag",
+			res: Result{
+				Tag: "v1.2.0", Current: v1, Next: Version{Major: 1, Minor: 3},
+				Commits: testCommits(7), Commit: hash,
+			},
+			want: "v1.3.0-dev.7+g1a2b3c4",
+		},
+		{
+			name: "commits without bump",
+			res: Result{
+				Tag: "v1.2.0", Current: v1, Next: v1,
+				Commits: testCommits(2), Commit: hash, Dirty: true,
+			},
+			want: "v1.2.1-dev.2+g1a2b3c4.dirty",
+		},
+		{
+			name: "prerelease tag on HEAD newer than the release tag",
+			res: Result{
+				Tag: "v1.2.0", HeadTag: "v1.3.0-next.1", Current: v1, Next: Version{Major: 1, Minor: 3, Prerelease: "next.1"},
+				Commits: testCommits(2), Commit: hash,
+			},
+			want: "v1.3.0-next.1",
+		},
+		{
+			name: "commits since a prerelease of the branch",
+			res: Result{
+				Tag: "v1.2.0", Current: v1, Next: Version{Major: 1, Minor: 3, Prerelease: "next.2"},
+				PrereleaseTag: "v1.3.0-next.1", PrereleaseCommits: 1,
+				Commits: testCommits(4), Commit: hash,
+			},
+			want: "v1.3.0-next.1.dev.1+g1a2b3c4",
+		},
+		{
+			name: "prerelease branch without prerelease tag",
+			res: Result{
+				Tag: "v1.2.0", Current: v1, Next: Version{Major: 1, Minor: 3, Prerelease: "next.1"},
+				Commits: testCommits(2), Commit: hash,
+			},
+			want: "v1.3.0-dev.2+g1a2b3c4",
+		},
+		{
+			name: "commits since a prerelease tag",
+			res: Result{
+				Tag: "v1.3.0-rc.1", Current: Version{Major: 1, Minor: 3, Prerelease: "rc.1"}, Next: Version{Major: 1, Minor: 3},
+				Commits: testCommits(2), Commit: hash,
+			},
+			want: "v1.3.0-rc.1.dev.2+g1a2b3c4",
+		},
+		{
+			name: "no tag",
+			res: Result{
+				Next:    Version{Minor: 1},
+				Commits: testCommits(3), Commit: hash,
+			},
+			want: "v0.1.0-dev.3+g1a2b3c4",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.res.Describe().String(); got != tt.want {
+				t.Errorf("Describe() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCompute_CalVer(t *testing.T) {
+	dir := newTestRepo(t, "feat: initial", "tag:v2026.10.0", "fix: bug")
+
+	cfg := &Config{
+		Patch:  []string{`^fix:`},
+		Scheme: "calver",
+	}
+	now := time.Date(2026, 10, 18, 0, 0, 0, 0, time.UTC)
+
+	res, err := Compute(context.Background(), dir, Options{Config: cfg, Now: now})
+	if err != nil {
+		t.Fatalf("Compute() error = %v", err)
+	}
+	if got := res.Next.String(); got != "v2026.10.1" {
+		t.Errorf("Compute().Next = %v, want v2026.10.1", got)
+	}
+
+	res, err = Compute(context.Background(), dir, Options{Config: cfg, Now: now.AddDate(0, 1, 0)})
+	if err != nil {
+		t.Fatalf("Compute() error = %v", err)
+	}
+	if got := res.Next.String(); got != "v2026.11.0" {
+		t.Errorf("Compute().Next = %v, want v2026.11.0", got)
+	}
+}
+
+func TestCompute_BranchPolicy(t *testing.T) {
+	dir := newTestRepo(t,
+		"feat: initial", "tag:v1.2.0",
+		"feat: next feature", "tag:v1.3.0-next.1",
+		"fix: next fix",
+	)
+
+	cfg := &Config{
+		Minor: []string{`^feat:`},
+		Patch: []string{`^fix:`},
+		Branches: []BranchPolicy{
+			{Name: "main"},
+			{Name: "next", Prerelease: "next"},
+			{Name: "release/1.x", Max: "1.3.0"},
+			{Name: "*", Prerelease: "{{branch}}"},
+		},
+	}
+
+	tests := []struct {
+		branch  string
+		want    string
+		wantErr bool
+	}{
+		{branch: "main", want: "v1.3.0"},
+		{branch: "next", want: "v1.3.0-next.2"},
+		{branch: "feature/Login_Form", want: "v1.3.0-feature-login-form.1"},
+		{branch: "release/1.x", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.branch, func(t *testing.T) {
+			res, err := Compute(context.Background(), dir, Options{Config: cfg, Branch: tt.branch})
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Compute() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			if res.Tag != "v1.2.0" {
+				t.Errorf("Compute().Tag = %v, want v1.2.0", res.Tag)
+			}
+			if got := res.Next.String(); got != tt.want {
+				t.Errorf("Compute().Next = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCompute_MaintenanceBranch(t *testing.T) {
+	dir := newTestRepo(t, "feat: initial", "tag:v1.4.0", "feat: new", "tag:v1.5.0")
+	runGit(t, dir, "checkout", "-q", "-b", "release/1.4.x", "v1.4.0")
+	runGit(t, dir, "commit", "-q", "--allow-empty", "-m", "feat: backport")
+
+	tests := []struct {
+		name    string
+		policy  BranchPolicy
+		want    string
+		wantErr bool
+	}{
+		{
+			name:    "collision with existing tag",
+			policy:  BranchPolicy{Name: "release/*"},
+			wantErr: true,
+		},
+		{
+			name:   "collision downgraded to patch",
+			policy: BranchPolicy{Name: "release/*", OnViolation: "patch"},
+			want:   "v1.4.1",
+		},
+		{
+			name:    "out of range",
+			policy:  BranchPolicy{Name: "release/*", Range: ">=1.4.0 <1.4.1"},
+			wantErr: true,
+		},
+		{
+			name:   "out of range downgraded to patch",
+			policy: BranchPolicy{Name: "release/*", Range: ">=1.4.0 <1.5.0", OnViolation: "patch"},
+			want:   "v1.4.1",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := &Config{
+				Minor:    []string{`^feat:`},
+				Branches: []BranchPolicy{tt.policy},
+			}
+
+			res, err := Compute(context.Background(), dir, Options{Config: cfg})
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Compute() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				var violation *PolicyViolationError
+				if !errors.As(err, &violation) {
+					t.Errorf("Compute() error = %v, want PolicyViolationError", err)
+				}
+				return
+			}
+			if got := res.Next.String(); got != tt.want {
+				t.Errorf("Compute().Next = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCompute_History(t *testing.T) {
+	dir := newTestRepo(t, "feat: initial", "tag:v1.0.0")
+	runGit(t, dir, "checkout", "-q", "-b", "login")
+	runGit(t, dir, "commit", "-q", "--allow-empty", "-m", "feat!: try a redesign")
+	runGit(t, dir, "commit", "-q", "--allow-empty", "-m", "fix: undo the redesign")
+	runGit(t, dir, "checkout", "-q", "-")
+	runGit(t, dir, "merge", "-q", "--no-ff", "login", "-m", "Merge pull request #12 from org/login", "-m", "fix: handle expired sessions")
+
+	tests := []struct {
+		name        string
+		history     string
+		merges      string
+		want        string
+		wantSubject string
+	}{
+		{name: "all commits", want: "v2.0.0", wantSubject: "Merge pull request #12 from org/login"},
+		{name: "first parent", history: "first-parent", want: "v1.0.0", wantSubject: "Merge pull request #12 from org/login"},
+		{name: "first parent by pull request", history: "first-parent", merges: "pull-request", want: "v1.0.1", wantSubject: "fix: handle expired sessions"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := &Config{
+				Major:        []string{`^.+!:`},
+				Patch:        []string{`^fix:`},
+				History:      tt.history,
+				MergeCommits: tt.merges,
+			}
+
+			res, err := Compute(context.Background(), dir, Options{Config: cfg})
+			if err != nil {
+				t.Fatalf("Compute() error = %v", err)
+			}
+			if got := res.Next.String(); got != tt.want {
+				t.Errorf("Compute().Next = %v, want %v", got, tt.want)
+			}
+			last := res.Commits[len(res.Commits)-1]
+			if !last.Merge || last.Subject != tt.wantSubject {
+				t.Errorf("last commit = %+v, want merge %q", last, tt.wantSubject)
+			}
+		})
+	}
+}
+
+func TestCompute_SquashCommits(t *testing.T) {
+	dir := newTestRepo(t, "feat: initial", "tag:v1.0.0", "Login form (#3)\n\n* fix: validate email\n\n* feat(api): add session endpoint\n\n* wip")
+
+	tests := []struct {
+		squash      string
+		want        string
+		wantCommits int
+	}{
+		{squash: "", want: "v1.0.0", wantCommits: 1},
+		{squash: "expand", want: "v1.1.0", wantCommits: 2},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.squash, func(t *testing.T) {
+			cfg := &Config{
+				Minor:         []string{`^feat(\(.+\))?:`},
+				Patch:         []string{`^fix(\(.+\))?:`},
+				SquashCommits: tt.squash,
+			}
+
+			res, err := Compute(context.Background(), dir, Options{Config: cfg})
+			if err != nil {
+				t.Fatalf("Compute() error = %v", err)
+			}
+			if got := res.Next.String(); got != tt.want {
+				t.Errorf("Compute().Next = %v, want %v", got, tt.want)
+			}
+			if len(res.Commits) != tt.wantCommits {
+				t.Fatalf("Compute().Commits = %+v, want %d commits", res.Commits, tt.wantCommits)
+			}
+			if tt.squash != "" && res.Commits[1].Subject != "feat(api): add session endpoint" {
+				t.Errorf("Compute().Commits[1].Subject = %q", res.Commits[1].Subject)
+			}
+		})
+	}
+}
+
+func TestCompute_SquashTitle(t *testing.T) {
+	tests := []struct {
+		name         string
+		message      string
+		want         string
+		wantSubjects []string
+	}{
+		{
+			name:         "breaking title",
+			message:      "feat!: drop v1 API (#12)\n\n* fix: a\n* fix: b",
+			want:         "v2.0.0",
+			wantSubjects: []string{"feat!: drop v1 API (#12)", "fix: a", "fix: b"},
+		},
+		{
+			name:         "title covered by bullets",
+			message:      "feat: login (#13)\n\n* feat: form\n* fix: typo",
+			want:         "v1.1.0",
+			wantSubjects: []string{"feat: form", "fix: typo"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := newTestRepo(t, "feat: initial", "tag:v1.0.0", tt.message)
+			cfg := &Config{
+				Major:         []string{`^\w+(\(.+\))?!:`},
+				Minor:         []string{`^feat(\(.+\))?:`},
+				Patch:         []string{`^fix(\(.+\))?:`},
+				SquashCommits: "expand",
+			}
+
+			res, err := Compute(context.Background(), dir, Options{Config: cfg})
+			if err != nil {
+				t.Fatalf("Compute() error = %v", err)
+			}
+			if got := res.Next.String(); got != tt.want {
+				t.Errorf("Compute().Next = %v, want %v", got, tt.want)
+			}
+			var subjects []string
+			for _, c := range res.Commits {
+				subjects = append(subjects, c.Subject)
+			}
+			if !reflect.DeepEqual(subjects, tt.wantSubjects) {
+				t.Errorf("Compute().Commits subjects = %q, want %q", subjects, tt.wantSubjects)
+			}
+			if got := CountCommits(res.Commits); got != 1 {
+				t.Errorf("CountCommits() = %d, want 1", got)
+			}
+		})
+	}
+}
+
+func TestCompute_PathFilters(t *testing.T) {
+	dir := newTestRepo(t, "feat: initial", "tag:v1.0.0")
+	commit := func(file, message string) {
+		t.Helper()
+		path := filepath.Join(dir, file)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(message), 0644); err != nil {
+			t.Fatal(err)
+		}
+		runGit(t, dir, "add", "-A")
+		runGit(t, dir, "commit", "-q", "-m", message)
+	}
+	commit("docs/guide.md", "feat: document login")
+	commit("main_test.go", "fix: flaky test")
+	commit("main.go", "fix: crash on start")
+
+	tests := []struct {
+		name    string
+		include []string
+		exclude []string
+		want    string
+	}{
+		{name: "no filters", want: "v1.1.0"},
+		{name: "excluded docs and tests", exclude: []string{"docs/", "*_test.go"}, want: "v1.0.1"},
+		{name: "included docs", include: []string{"docs/**"}, want: "v1.1.0"},
+		{name: "nothing included", include: []string{"src/**"}, want: "v1.0.0"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := &Config{
+				Minor:        []string{`^feat:`},
+				Patch:        []string{`^fix:`},
+				IncludePaths: tt.include,
+				ExcludePaths: tt.exclude,
+			}
+
+			res, err := Compute(context.Background(), dir, Options{Config: cfg})
+			if err != nil {
+				t.Fatalf("Compute() error = %v", err)
+			}
+			if got := res.Next.String(); got != tt.want {
+				t.Errorf("Compute().Next = %v, want %v", got, tt.want)
+			}
+			if len(res.Commits) != 3 {
+				t.Errorf("Compute().Commits has %d commits, want 3", len(res.Commits))
+			}
+		})
+	}
+}
+
+func TestCompute_ShallowClone(t *testing.T) {
+	remote := newTestRepo(t, "feat: initial", "tag:v1.2.0", "fix: a", "fix: b", "fix: c")
+
+	clone := func(t *testing.T) string {
+		dir := filepath.Join(t.TempDir(), "clone")
+		runGit(t, t.TempDir(), "clone", "-q", "--depth", "1", "file://"+remote, dir)
+		return dir
+	}
+
+	t.Run("fails without deepen", func(t *testing.T) {
+		_, err := Compute(context.Background(), clone(t), Options{})
+		if !errors.Is(err, ErrShallow) {
+			t.Fatalf("Compute() error = %v, want ErrShallow", err)
+		}
+	})
+
+	t.Run("deepens until a tag is reachable", func(t *testing.T) {
+		res, err := Compute(context.Background(), clone(t), Options{Deepen: 1})
+		if err != nil {
+			t.Fatalf("Compute() error = %v", err)
+		}
+		if res.Tag != "v1.2.0" || res.Next.String() != "v1.2.1" {
+			t.Errorf("Compute() = %s -> %s, want v1.2.0 -> v1.2.1", res.Tag, res.Next)
+		}
+		if len(res.Commits) != 3 {
+			t.Errorf("Compute().Commits has %d commits, want 3", len(res.Commits))
+		}
+	})
+}
+
+func TestCompute_LeftoverPrerelease(t *testing.T) {
+	tests := []struct {
+		name  string
+		steps []string
+		want  string
+	}{
+		{name: "no commits", steps: []string{"feat: initial", "tag:v1.0.1-rc.1"}, want: "v1.0.1"},
+		{name: "metadata", steps: []string{"feat: initial", "tag:v1.0.1+build.5"}, want: "v1.0.1"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := newTestRepo(t, tt.steps...)
+			cfg := &Config{Patch: []string{`^fix:`}}
+
+			res, err := Compute(context.Background(), dir, Options{Config: cfg})
+			if err != nil {
+				t.Fatalf("Compute() error = %v", err)
+			}
+			if got := res.Next.String(); got != tt.want {
+				t.Errorf("Compute().Next = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCompute_PrereleaseTaggedHead(t *testing.T) {
+	dir := newTestRepo(t, "feat: initial", "tag:v1.0.0", "feat: next feature")
+	cfg := &Config{
+		Minor:    []string{`^feat:`},
+		Branches: []BranchPolicy{{Name: "next", Prerelease: "next"}},
+	}
+
+	// Tag twice, as a re-run of a release job would
+	for range 2 {
+		res, err := Compute(context.Background(), dir, Options{Config: cfg, Branch: "next"})
+		if err != nil {
+			t.Fatalf("Compute() error = %v", err)
+		}
+		if got := res.Next.String(); got != "v1.1.0-next.1" {
+			t.Fatalf("Compute().Next = %v, want v1.1.0-next.1", got)
+		}
+		tags, err := git.ListTagsPointingAt(context.Background(), dir, "HEAD", res.Next.String())
+		if err != nil {
+			t.Fatalf("ListTagsPointingAt() error = %v", err)
+		}
+		if len(tags) == 0 {
+			runGit(t, dir, "tag", res.Next.String())
+		}
+	}
+
+	runGit(t, dir, "commit", "-q", "--allow-empty", "-m", "feat: another")
+	res, err := Compute(context.Background(), dir, Options{Config: cfg, Branch: "next"})
+	if err != nil {
+		t.Fatalf("Compute() error = %v", err)
+	}
+	if got := res.Next.String(); got != "v1.1.0-next.2" {
+		t.Errorf("Compute().Next = %v, want v1.1.0-next.2", got)
+	}
+}
+
+func TestCompute_DescribePrereleaseTag(t *testing.T) {
+	dir := newTestRepo(t, "feat: initial", "tag:v1.0.0", "feat: next feature", "tag:v1.1.0-next.1")
+	cfg := &Config{
+		Minor:    []string{`^feat:`},
+		Branches: []BranchPolicy{{Name: "next", Prerelease: "next"}},
+	}
+
+	res, err := Compute(context.Background(), dir, Options{Config: cfg, Branch: "next"})
+	if err != nil {
+		t.Fatalf("Compute() error = %v", err)
+	}
+	if res.HeadTag != "v1.1.0-next.1" {
+		t.Errorf("Compute().HeadTag = %v, want v1.1.0-next.1", res.HeadTag)
+	}
+	if got := res.Describe().String(); got != "v1.1.0-next.1" {
+		t.Errorf("Describe() = %v, want v1.1.0-next.1", got)
+	}
+
+	// A development version sorts above the prerelease it follows
+	runGit(t, dir, "commit", "-q", "--allow-empty", "-m", "fix: bug")
+	res, err = Compute(context.Background(), dir, Options{Config: cfg, Branch: "next"})
+	if err != nil {
+		t.Fatalf("Compute() error = %v", err)
+	}
+	if res.PrereleaseTag != "v1.1.0-next.1" || res.PrereleaseCommits != 1 {
+		t.Errorf("Compute() prerelease = %v, %d commits, want v1.1.0-next.1, 1 commit", res.PrereleaseTag, res.PrereleaseCommits)
+	}
+	want := "v1.1.0-next.1.dev.1+g" + res.Commit[:7]
+	if got := res.Describe().String(); got != want {
+		t.Errorf("Describe() = %v, want %v", got, want)
+	}
+}
+
+func TestCompute_DescribeSquashCommits(t *testing.T) {
+	dir := newTestRepo(t,
+		"feat: initial", "tag:v1.0.0",
+		"Login form (#3)\n\n* fix: validate email\n\n* feat(api): add session endpoint\n\n* fix: typo",
+		"fix: bug",
+	)
+	cfg := &Config{
+		Minor:         []string{`^feat(\(.+\))?:`},
+		Patch:         []string{`^fix(\(.+\))?:`},
+		SquashCommits: "expand",
+	}
+
+	res, err := Compute(context.Background(), dir, Options{Config: cfg})
+	if err != nil {
+		t.Fatalf("Compute() error = %v", err)
+	}
+	if len(res.Commits) != 4 {
+		t.Fatalf("Compute().Commits = %+v, want 4 commits", res.Commits)
+	}
+	if got := CountCommits(res.Commits); got != 2 {
+		t.Errorf("CountCommits() = %d, want 2", got)
+	}
+	want := "v1.1.0-dev.2+g" + res.Commit[:7]
+	if got := res.Describe().String(); got != want {
+		t.Errorf("Describe() = %v, want %v", got, want)
+	}
+}
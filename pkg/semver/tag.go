@@ -0,0 +1,25 @@
+package semver
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/TheScenery/sem-version/internal/git"
+)
+
+// CreateTag creates the annotated tag name on HEAD, such as the next version
+// of a Result. A re-run on an already released commit leaves its tag as is
+// and reports false.
+func CreateTag(ctx context.Context, repoPath, name, message string) (bool, error) {
+	existing, err := git.ListTagsPointingAt(ctx, repoPath, "HEAD", name)
+	if err != nil {
+		return false, fmt.Errorf("listing tags: %w", err)
+	}
+	if len(existing) > 0 {
+		return false, nil
+	}
+	if err := git.CreateTag(ctx, repoPath, name, message); err != nil {
+		return false, fmt.Errorf("creating tag %s: %w", name, err)
+	}
+	return true, nil
+}
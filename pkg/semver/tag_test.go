@@ -0,0 +1,30 @@
+package semver
+
+import (
+	"context"
+	"testing"
+
+	"github.com/TheScenery/sem-version/internal/git"
+)
+
+func TestCreateTag(t *testing.T) {
+	dir := newTestRepo(t, "feat: initial")
+
+	for i, want := range []bool{true, false} {
+		created, err := CreateTag(context.Background(), dir, "v0.1.0", "Release v0.1.0")
+		if err != nil {
+			t.Fatalf("CreateTag() error = %v", err)
+		}
+		if created != want {
+			t.Errorf("CreateTag() run %d = %v, want %v", i+1, created, want)
+		}
+	}
+
+	tags, err := git.ListTags(context.Background(), dir, "v*")
+	if err != nil {
+		t.Fatalf("ListTags() error = %v", err)
+	}
+	if len(tags) != 1 || tags[0] != "v0.1.0" {
+		t.Errorf("tags = %q, want [v0.1.0]", tags)
+	}
+}
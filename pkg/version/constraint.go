@@ -0,0 +1,242 @@
+package version
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// Constraint is a version range such as ">=1.4.0 <1.5.0", "~1.4", "^0.3.1"
+// or "1.x || >=3.0.0". A version satisfies it when it satisfies all
+// comparators of at least one of the ||-separated sets.
+type Constraint struct {
+	raw  string
+	sets [][]comparator
+}
+
+// comparator compares a version against a bound with an operator
+type comparator struct {
+	op    string
+	bound Version
+}
+
+// operators lists the supported operators, longest first for parsing
+var operators = []string{">=", "<=", ">", "<", "=", "~", "^"}
+
+// partialRegex matches a possibly partial version such as "1", "1.4",
+// "1.x" or "1.4.2-rc.1", where x, X and * are wildcards
+var partialRegex = regexp.MustCompile(`^v?(\d+|[xX*])(?:\.(\d+|[xX*]))?(?:\.(\d+|[xX*]))?(?:-([0-9A-Za-z.-]+))?(?:\+[0-9A-Za-z.-]+)?$`)
+
+// ParseConstraint parses a constraint: ||-separated sets of space-separated
+// comparators. Comparators use =, >, >=, <, <=, ~ (patch updates), ^
+// (compatible updates) or no operator, and accept partial versions with
+// wildcards such as "1.x".
+func ParseConstraint(s string) (Constraint, error) {
+	c := Constraint{raw: strings.TrimSpace(s)}
+
+	for _, part := range strings.Split(s, "||") {
+		set, err := parseSet(part)
+		if err != nil {
+			return Constraint{}, fmt.Errorf("invalid constraint %q: %w", s, err)
+		}
+		c.sets = append(c.sets, set)
+	}
+	return c, nil
+}
+
+// parseSet parses space-separated comparators, allowing a space between an
+// operator and its version
+func parseSet(s string) ([]comparator, error) {
+	fields := strings.Fields(s)
+	if len(fields) == 0 {
+		return nil, fmt.Errorf("empty range")
+	}
+
+	var set []comparator
+	for i := 0; i < len(fields); i++ {
+		field := fields[i]
+		op := ""
+		for _, o := range operators {
+			if strings.HasPrefix(field, o) {
+				op = o
+				break
+			}
+		}
+		if field == op {
+			if i+1 == len(fields) {
+				return nil, fmt.Errorf("operator %s without version", op)
+			}
+			i++
+			field += fields[i]
+		}
+
+		comparators, err := expand(op, strings.TrimPrefix(field, op))
+		if err != nil {
+			return nil, err
+		}
+		set = append(set, comparators...)
+	}
+	return set, nil
+}
+
+// partial is a version whose trailing components may be missing or wildcards
+type partial struct {
+	Version
+	// parts is the number of specified components (0 to 3)
+	parts int
+}
+
+func parsePartial(s string) (partial, error) {
+	m := partialRegex.FindStringSubmatch(s)
+	if m == nil {
+		return partial{}, fmt.Errorf("invalid version %q", s)
+	}
+
+	var p partial
+	nums := []*int{&p.Major, &p.Minor, &p.Patch}
+	for i, part := range m[1:4] {
+		if part == "" || part == "x" || part == "X" || part == "*" {
+			break
+		}
+		*nums[i], _ = strconv.Atoi(part)
+		p.parts++
+	}
+
+	if m[4] != "" {
+		if p.parts < 3 {
+			return partial{}, fmt.Errorf("invalid version %q: prerelease needs a full version", s)
+		}
+		p.Prerelease = m[4]
+	}
+	return p, nil
+}
+
+// expand turns an operator and a possibly partial version into plain comparators
+func expand(op, v string) ([]comparator, error) {
+	p, err := parsePartial(v)
+	if err != nil {
+		return nil, err
+	}
+
+	lower := p.Version
+	switch op {
+	case "~":
+		// ~1.4.2 := >=1.4.2 <1.5.0, ~1 := >=1.0.0 <2.0.0
+		if p.parts <= 1 {
+			return between(lower, p.BumpMajor(), p.parts), nil
+		}
+		return between(lower, p.BumpMinor(), p.parts), nil
+
+	case "^":
+		// ^1.2.3 := >=1.2.3 <2.0.0, ^0.3.1 := >=0.3.1 <0.4.0, ^0.0.3 := >=0.0.3 <0.0.4
+		switch {
+		case p.Major > 0 || p.parts <= 1:
+			return between(lower, p.BumpMajor(), p.parts), nil
+		case p.Minor > 0 || p.parts == 2:
+			return between(lower, p.BumpMinor(), p.parts), nil
+		default:
+			return between(lower, p.BumpPatch(), p.parts), nil
+		}
+
+	case "", "=":
+		if p.parts == 3 {
+			return []comparator{{"=", lower}}, nil
+		}
+		return between(lower, p.upper(), p.parts), nil
+
+	case ">":
+		if p.parts == 3 {
+			return []comparator{{">", lower}}, nil
+		}
+		if p.parts == 0 {
+			// Nothing is greater than any version
+			return []comparator{{"<", Version{}}}, nil
+		}
+		return []comparator{{">=", p.upper()}}, nil
+
+	case ">=":
+		return []comparator{{">=", lower}}, nil
+
+	case "<":
+		if p.parts == 0 {
+			return []comparator{{"<", Version{}}}, nil
+		}
+		return []comparator{{"<", lower}}, nil
+
+	case "<=":
+		if p.parts == 3 {
+			return []comparator{{"<=", lower}}, nil
+		}
+		if p.parts == 0 {
+			return nil, nil
+		}
+		return []comparator{{"<", p.upper()}}, nil
+	}
+
+	return nil, fmt.Errorf("unknown operator %q", op)
+}
+
+// upper returns the first version after the range covered by a partial
+// version, e.g. 2.0.0 for 1.x and 1.5.0 for 1.4.x
+func (p partial) upper() Version {
+	if p.parts <= 1 {
+		return p.BumpMajor()
+	}
+	return p.BumpMinor()
+}
+
+// between returns the comparators of [lower, upper), or no comparator for
+// a full wildcard
+func between(lower, upper Version, parts int) []comparator {
+	if parts == 0 {
+		return nil
+	}
+	return []comparator{{">=", lower}, {"<", upper}}
+}
+
+// Check reports whether v satisfies the constraint
+func (c Constraint) Check(v Version) bool {
+	for _, set := range c.sets {
+		if checkSet(set, v) {
+			return true
+		}
+	}
+	return false
+}
+
+func checkSet(set []comparator, v Version) bool {
+	for _, cmp := range set {
+		if !cmp.check(v) {
+			return false
+		}
+	}
+	return true
+}
+
+// String returns the constraint as written
+func (c Constraint) String() string {
+	return c.raw
+}
+
+func (cmp comparator) check(v Version) bool {
+	// "<1.5.0" excludes the prereleases of 1.5.0 too: they are part of the
+	// 1.5.0 release line, which is what an upper bound is meant to keep out
+	if cmp.op == "<" && cmp.bound.Prerelease == "" {
+		v.Prerelease = ""
+	}
+
+	c := Compare(v, cmp.bound)
+	switch cmp.op {
+	case ">=":
+		return c >= 0
+	case "<=":
+		return c <= 0
+	case ">":
+		return c > 0
+	case "<":
+		return c < 0
+	default:
+		return c == 0
+	}
+}
@@ -0,0 +1,79 @@
+package version
+
+import (
+	"testing"
+)
+
+func TestConstraint_Check(t *testing.T) {
+	tests := []struct {
+		constraint string
+		version    string
+		want       bool
+	}{
+		{">=1.4.0 <1.5.0", "1.4.0", true},
+		{">=1.4.0 <1.5.0", "1.4.9", true},
+		{">=1.4.0 <1.5.0", "1.5.0", false},
+		{">=1.4.0 <1.5.0", "1.5.0-rc.1", false},
+		{">=1.4.0 <1.5.0", "1.3.9", false},
+		{"<2.0.0", "1.99.0", true},
+		{"<2.0.0", "2.0.0-next.1", false},
+		{"<2.0.0-rc.1", "2.0.0-beta.1", true},
+		{">1.0.0", "1.0.0", false},
+		{">1.0.0", "1.0.1", true},
+		{"<=1.0.0", "1.0.0", true},
+		{"=1.2.3", "1.2.3+build.1", true},
+		{"1.2.3", "1.2.4", false},
+		{"v1.2.3", "1.2.3", true},
+		{">= 1.2.0 < 2.0.0", "1.9.0", true},
+		{"~1.4", "1.4.7", true},
+		{"~1.4", "1.5.0", false},
+		{"~1.4.2", "1.4.1", false},
+		{"~1", "1.9.0", true},
+		{"^0.3.1", "0.3.9", true},
+		{"^0.3.1", "0.4.0", false},
+		{"^0.0.3", "0.0.4", false},
+		{"^1.2.3", "1.9.0", true},
+		{"^1.2.3", "2.0.0", false},
+		{"^1.2.3", "1.2.2", false},
+		{"1.x", "1.7.3", true},
+		{"1.x", "2.0.0", false},
+		{"1.2.*", "1.2.9", true},
+		{"1.2.*", "1.3.0", false},
+		{"1", "1.4.0", true},
+		{"*", "0.0.1", true},
+		{">=1.2", "1.2.0", true},
+		{">1.2", "1.2.9", false},
+		{">1.2", "1.3.0", true},
+		{"<=1.2", "1.2.9", true},
+		{"<=1.2", "1.3.0", false},
+		{"1.x || >=3.0.0", "2.1.0", false},
+		{"1.x || >=3.0.0", "3.1.0", true},
+		{"1.x || >=3.0.0", "1.1.0", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.constraint+" "+tt.version, func(t *testing.T) {
+			c, err := ParseConstraint(tt.constraint)
+			if err != nil {
+				t.Fatalf("ParseConstraint() error = %v", err)
+			}
+			v, err := Parse(tt.version)
+			if err != nil {
+				t.Fatalf("Parse() error = %v", err)
+			}
+			if got := c.Check(v); got != tt.want {
+				t.Errorf("Check() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseConstraint_Invalid(t *testing.T) {
+	for _, s := range []string{"", "  ", "<x.y.z", ">=1.0.0 <", "~", "1.2.3.4", "1.2-rc.1", "1.x ||", "=>1.0.0"} {
+		t.Run(s, func(t *testing.T) {
+			if _, err := ParseConstraint(s); err == nil {
+				t.Errorf("ParseConstraint(%q) expected error", s)
+			}
+		})
+	}
+}
@@ -0,0 +1,113 @@
+package version
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Scheme computes the next version of a release
+type Scheme interface {
+	// Next returns the version following current for the given bump.
+	// hasCurrent is false when there is no previous release.
+	Next(current Version, hasCurrent bool, bump BumpType, now time.Time) Version
+}
+
+// SemVer is the Semantic Versioning scheme: the bump type selects the
+// component to increment
+type SemVer struct{}
+
+// Next implements Scheme
+func (SemVer) Next(current Version, hasCurrent bool, bump BumpType, now time.Time) Version {
+	if !hasCurrent {
+		switch bump {
+		case BumpMajorType:
+			return Version{Major: 1, Minor: 0, Patch: 0}
+		case BumpPatchType:
+			return Version{Major: 0, Minor: 0, Patch: 1}
+		default:
+			return DefaultInitialVersion()
+		}
+	}
+
+	switch bump {
+	case BumpMajorType:
+		return current.BumpMajor()
+	case BumpMinorType:
+		return current.BumpMinor()
+	case BumpPatchType:
+		return current.BumpPatch()
+	default:
+		return current
+	}
+}
+
+// CalVer is a Calendar Versioning scheme such as YYYY.MM.MICRO: the major and
+// minor components come from the release date, and the bump type only
+// decides whether MICRO is incremented within the same period
+type CalVer struct {
+	major, minor string
+	// isoWeek takes the year from the ISO week with WW, as in the last days
+	// of December belonging to week 1 of the next year
+	isoWeek bool
+}
+
+// calverTokens maps the supported date tokens to their value for a date
+var calverTokens = map[string]func(time.Time) int{
+	"YYYY": func(t time.Time) int { return t.Year() },
+	"YY":   func(t time.Time) int { return t.Year() % 100 },
+	"MM":   func(t time.Time) int { return int(t.Month()) },
+	"WW":   func(t time.Time) int { _, w := t.ISOWeek(); return w },
+	"DD":   func(t time.Time) int { return t.Day() },
+}
+
+// NewCalVer creates a CalVer scheme from a format of two date tokens and
+// MICRO, e.g. "YYYY.MM.MICRO" or "YY.WW.MICRO"
+func NewCalVer(format string) (*CalVer, error) {
+	parts := strings.Split(format, ".")
+	if len(parts) != 3 || parts[2] != "MICRO" {
+		return nil, fmt.Errorf("invalid calver format %q: want <date>.<date>.MICRO, e.g. YYYY.MM.MICRO", format)
+	}
+	for _, p := range parts[:2] {
+		if _, ok := calverTokens[p]; !ok {
+			if strings.HasPrefix(p, "0") {
+				return nil, fmt.Errorf("invalid calver format %q: zero-padded token %s is not valid SemVer", format, p)
+			}
+			return nil, fmt.Errorf("invalid calver format %q: unknown token %s (want YYYY, YY, MM, WW or DD)", format, p)
+		}
+	}
+	return &CalVer{major: parts[0], minor: parts[1], isoWeek: parts[0] == "WW" || parts[1] == "WW"}, nil
+}
+
+// value returns the value of a date token of the format for a date
+func (c *CalVer) value(token string, t time.Time) int {
+	if c.isoWeek {
+		year, _ := t.ISOWeek()
+		switch token {
+		case "YYYY":
+			return year
+		case "YY":
+			return year % 100
+		}
+	}
+	return calverTokens[token](t)
+}
+
+// Next implements Scheme
+func (c *CalVer) Next(current Version, hasCurrent bool, bump BumpType, now time.Time) Version {
+	period := Version{
+		Major: c.value(c.major, now),
+		Minor: c.value(c.minor, now),
+	}
+
+	if !hasCurrent {
+		return period
+	}
+	if bump == BumpNone {
+		return current
+	}
+	if current.Major == period.Major && current.Minor == period.Minor {
+		return Version{Major: current.Major, Minor: current.Minor, Patch: current.Patch + 1}
+	}
+	return period
+}
@@ -0,0 +1,106 @@
+package version
+
+import (
+	"testing"
+	"time"
+)
+
+func TestSemVer_Next(t *testing.T) {
+	current := Version{Major: 1, Minor: 2, Patch: 3}
+	now := time.Now()
+
+	tests := []struct {
+		name       string
+		hasCurrent bool
+		bump       BumpType
+		want       Version
+	}{
+		{"initial minor", false, BumpMinorType, Version{Major: 0, Minor: 1, Patch: 0}},
+		{"initial major", false, BumpMajorType, Version{Major: 1, Minor: 0, Patch: 0}},
+		{"initial patch", false, BumpPatchType, Version{Major: 0, Minor: 0, Patch: 1}},
+		{"initial none", false, BumpNone, Version{Major: 0, Minor: 1, Patch: 0}},
+		{"patch", true, BumpPatchType, Version{Major: 1, Minor: 2, Patch: 4}},
+		{"minor", true, BumpMinorType, Version{Major: 1, Minor: 3, Patch: 0}},
+		{"major", true, BumpMajorType, Version{Major: 2, Minor: 0, Patch: 0}},
+		{"none", true, BumpNone, current},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := (SemVer{}).Next(current, tt.hasCurrent, tt.bump, now); got != tt.want {
+				t.Errorf("Next() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCalVer_Next(t *testing.T) {
+	now := time.Date(2026, 10, 18, 12, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name       string
+		format     string
+		current    Version
+		hasCurrent bool
+		bump       BumpType
+		want       Version
+	}{
+		{"first release", "YYYY.MM.MICRO", Version{}, false, BumpNone, Version{Major: 2026, Minor: 10}},
+		{"same period bump", "YYYY.MM.MICRO", Version{Major: 2026, Minor: 10, Patch: 2}, true, BumpPatchType, Version{Major: 2026, Minor: 10, Patch: 3}},
+		{"same period major bump", "YYYY.MM.MICRO", Version{Major: 2026, Minor: 10, Patch: 2}, true, BumpMajorType, Version{Major: 2026, Minor: 10, Patch: 3}},
+		{"same period no bump", "YYYY.MM.MICRO", Version{Major: 2026, Minor: 10, Patch: 2}, true, BumpNone, Version{Major: 2026, Minor: 10, Patch: 2}},
+		{"new period", "YYYY.MM.MICRO", Version{Major: 2026, Minor: 9, Patch: 5}, true, BumpMinorType, Version{Major: 2026, Minor: 10}},
+		{"new period no bump", "YYYY.MM.MICRO", Version{Major: 2026, Minor: 9, Patch: 5}, true, BumpNone, Version{Major: 2026, Minor: 9, Patch: 5}},
+		{"short year", "YY.MM.MICRO", Version{Major: 26, Minor: 10}, true, BumpPatchType, Version{Major: 26, Minor: 10, Patch: 1}},
+		{"iso week", "YY.WW.MICRO", Version{}, false, BumpPatchType, Version{Major: 26, Minor: 42}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s, err := NewCalVer(tt.format)
+			if err != nil {
+				t.Fatalf("NewCalVer() error = %v", err)
+			}
+			if got := s.Next(tt.current, tt.hasCurrent, tt.bump, now); got != tt.want {
+				t.Errorf("Next() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCalVer_NextYearBoundary(t *testing.T) {
+	tests := []struct {
+		format string
+		now    time.Time
+		want   Version
+	}{
+		// 2024-12-30 is in week 1 of 2025, 2021-01-01 in week 53 of 2020
+		{"YYYY.WW.MICRO", time.Date(2024, 12, 30, 0, 0, 0, 0, time.UTC), Version{Major: 2025, Minor: 1}},
+		{"YY.WW.MICRO", time.Date(2024, 12, 30, 0, 0, 0, 0, time.UTC), Version{Major: 25, Minor: 1}},
+		{"YYYY.WW.MICRO", time.Date(2021, 1, 1, 0, 0, 0, 0, time.UTC), Version{Major: 2020, Minor: 53}},
+		{"YYYY.MM.MICRO", time.Date(2024, 12, 30, 0, 0, 0, 0, time.UTC), Version{Major: 2024, Minor: 12}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.format+" "+tt.now.Format(time.DateOnly), func(t *testing.T) {
+			s, err := NewCalVer(tt.format)
+			if err != nil {
+				t.Fatalf("NewCalVer() error = %v", err)
+			}
+			current := Version{Major: 2024, Minor: 52, Patch: 3}
+			if got := s.Next(current, true, BumpPatchType, tt.now); got != tt.want {
+				t.Errorf("Next() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNewCalVer_Invalid(t *testing.T) {
+	for _, format := range []string{"", "YYYY.MM", "YYYY.MM.DD", "YYYY.0M.MICRO", "YYYY.QQ.MICRO", "MICRO.YYYY.MM"} {
+		t.Run(format, func(t *testing.T) {
+			if _, err := NewCalVer(format); err == nil {
+				t.Errorf("NewCalVer(%q) expected error", format)
+			}
+		})
+	}
+}
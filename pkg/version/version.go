@@ -0,0 +1,266 @@
+// Package version parses, compares and bumps semantic versions, and
+// computes the next version of a versioning scheme such as CalVer.
+package version
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/TheScenery/sem-version/pkg/parser"
+)
+
+// Version represents a semantic version
+type Version struct {
+	Major      int
+	Minor      int
+	Patch      int
+	Prerelease string
+	Metadata   string
+}
+
+// semverRegex matches semantic version format
+var semverRegex = regexp.MustCompile(`^v?(\d+)\.(\d+)\.(\d+)(?:-([a-zA-Z0-9.-]+))?(?:\+([a-zA-Z0-9.-]+))?$`)
+
+// metadataRegex matches SemVer build metadata and prerelease: dot-separated
+// non-empty identifiers of alphanumerics and hyphens
+var metadataRegex = regexp.MustCompile(`^[0-9A-Za-z-]+(\.[0-9A-Za-z-]+)*$`)
+
+// ValidatePrerelease returns an error if p is not a valid SemVer prerelease
+func ValidatePrerelease(p string) error {
+	if !metadataRegex.MatchString(p) {
+		return fmt.Errorf("invalid prerelease %q: want dot-separated identifiers of [0-9A-Za-z-]", p)
+	}
+	return nil
+}
+
+// ValidateMetadata returns an error if m is not valid SemVer build metadata
+func ValidateMetadata(m string) error {
+	if !metadataRegex.MatchString(m) {
+		return fmt.Errorf("invalid build metadata %q: want dot-separated identifiers of [0-9A-Za-z-]", m)
+	}
+	return nil
+}
+
+// Parse parses a version string into a Version struct
+func Parse(v string) (Version, error) {
+	matches := semverRegex.FindStringSubmatch(v)
+	if matches == nil {
+		return Version{}, fmt.Errorf("invalid version format: %s", v)
+	}
+
+	major, _ := strconv.Atoi(matches[1])
+	minor, _ := strconv.Atoi(matches[2])
+	patch, _ := strconv.Atoi(matches[3])
+
+	return Version{
+		Major:      major,
+		Minor:      minor,
+		Patch:      patch,
+		Prerelease: matches[4],
+		Metadata:   matches[5],
+	}, nil
+}
+
+// String returns the version as a string with v prefix
+func (v Version) String() string {
+	base := fmt.Sprintf("v%d.%d.%d", v.Major, v.Minor, v.Patch)
+	if v.Prerelease != "" {
+		base += "-" + v.Prerelease
+	}
+	if v.Metadata != "" {
+		base += "+" + v.Metadata
+	}
+	return base
+}
+
+// StringWithoutPrefix returns the version as a string without v prefix
+func (v Version) StringWithoutPrefix() string {
+	return strings.TrimPrefix(v.String(), "v")
+}
+
+// BumpMajor increments the major version and resets minor and patch
+func (v Version) BumpMajor() Version {
+	return Version{
+		Major: v.Major + 1,
+		Minor: 0,
+		Patch: 0,
+	}
+}
+
+// BumpMinor increments the minor version and resets patch
+func (v Version) BumpMinor() Version {
+	return Version{
+		Major: v.Major,
+		Minor: v.Minor + 1,
+		Patch: 0,
+	}
+}
+
+// BumpPatch increments the patch version
+func (v Version) BumpPatch() Version {
+	return Version{
+		Major: v.Major,
+		Minor: v.Minor,
+		Patch: v.Patch + 1,
+	}
+}
+
+// BumpType represents the type of version bump
+type BumpType int
+
+const (
+	BumpNone BumpType = iota
+	BumpPatchType
+	BumpMinorType
+	BumpMajorType
+)
+
+// String returns the lowercase name of the bump type
+func (b BumpType) String() string {
+	switch b {
+	case BumpPatchType:
+		return "patch"
+	case BumpMinorType:
+		return "minor"
+	case BumpMajorType:
+		return "major"
+	default:
+		return "none"
+	}
+}
+
+// ParseBumpType parses the name of a bump type, as returned by String
+func ParseBumpType(s string) (BumpType, error) {
+	for _, b := range []BumpType{BumpNone, BumpPatchType, BumpMinorType, BumpMajorType} {
+		if s == b.String() {
+			return b, nil
+		}
+	}
+	return BumpNone, fmt.Errorf("invalid bump %q (want major, minor, patch or none)", s)
+}
+
+// CalculateNextVersion determines the next version based on parsed commits
+func CalculateNextVersion(current Version, commits []parser.ParsedCommit) Version {
+	bumpType := BumpNone
+
+	for _, commit := range commits {
+		// Breaking change always bumps major
+		if commit.IsBreaking {
+			bumpType = BumpMajorType
+			break // No need to check further
+		}
+
+		// feat bumps minor
+		if commit.Type == parser.TypeFeat && bumpType < BumpMinorType {
+			bumpType = BumpMinorType
+		}
+
+		// fix, refactor, perf bump patch
+		if (commit.Type == parser.TypeFix ||
+			commit.Type == parser.TypeRefactor ||
+			commit.Type == parser.TypePerf) && bumpType < BumpPatchType {
+			bumpType = BumpPatchType
+		}
+	}
+
+	switch bumpType {
+	case BumpMajorType:
+		return current.BumpMajor()
+	case BumpMinorType:
+		return current.BumpMinor()
+	case BumpPatchType:
+		return current.BumpPatch()
+	default:
+		return current
+	}
+}
+
+// DefaultInitialVersion returns the default initial version (v0.1.0)
+func DefaultInitialVersion() Version {
+	return Version{
+		Major: 0,
+		Minor: 1,
+		Patch: 0,
+	}
+}
+
+// Compare returns -1, 0 or 1 depending on whether a has lower, equal or
+// higher precedence than b. Build metadata is ignored, as per SemVer.
+func Compare(a, b Version) int {
+	for _, d := range []int{a.Major - b.Major, a.Minor - b.Minor, a.Patch - b.Patch} {
+		if d < 0 {
+			return -1
+		}
+		if d > 0 {
+			return 1
+		}
+	}
+	return comparePrerelease(a.Prerelease, b.Prerelease)
+}
+
+// comparePrerelease compares prerelease strings: a version without
+// prerelease has higher precedence, identifiers are compared one by one
+func comparePrerelease(a, b string) int {
+	switch {
+	case a == b:
+		return 0
+	case a == "":
+		return 1
+	case b == "":
+		return -1
+	}
+
+	as, bs := strings.Split(a, "."), strings.Split(b, ".")
+	for i := 0; i < len(as) && i < len(bs); i++ {
+		if c := compareIdentifier(as[i], bs[i]); c != 0 {
+			return c
+		}
+	}
+
+	switch {
+	case len(as) < len(bs):
+		return -1
+	case len(as) > len(bs):
+		return 1
+	default:
+		return 0
+	}
+}
+
+// compareIdentifier compares prerelease identifiers: numeric identifiers
+// compare numerically and have lower precedence than alphanumeric ones
+func compareIdentifier(a, b string) int {
+	an, aErr := strconv.Atoi(a)
+	bn, bErr := strconv.Atoi(b)
+
+	switch {
+	case aErr == nil && bErr == nil:
+		switch {
+		case an < bn:
+			return -1
+		case an > bn:
+			return 1
+		default:
+			return 0
+		}
+	case aErr == nil:
+		return -1
+	case bErr == nil:
+		return 1
+	default:
+		return strings.Compare(a, b)
+	}
+}
+
+// invalidIdentifierChars matches runs of characters not allowed in
+// prerelease and build metadata identifiers
+var invalidIdentifierChars = regexp.MustCompile(`[^0-9A-Za-z-]+`)
+
+// SanitizeIdentifier turns an arbitrary string such as a branch name into a
+// valid prerelease identifier, e.g. "feature/Login_Form" -> "feature-login-form"
+func SanitizeIdentifier(s string) string {
+	s = invalidIdentifierChars.ReplaceAllString(strings.ToLower(s), "-")
+	return strings.Trim(s, "-")
+}
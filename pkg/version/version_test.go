@@ -3,7 +3,7 @@ package version
 import (
 	"testing"
 
-	"github.com/TheScenery/sem-version/internal/parser"
+	"github.com/TheScenery/sem-version/pkg/parser"
 )
 
 func TestParse(t *testing.T) {
@@ -196,3 +196,94 @@ func TestCalculateNextVersion(t *testing.T) {
 		})
 	}
 }
+
+func TestValidateMetadata(t *testing.T) {
+	tests := []struct {
+		metadata string
+		wantErr  bool
+	}{
+		{"build.123", false},
+		{"g1a2b3c4.20261018", false},
+		{"exp-sha.5114f85", false},
+		{"", true},
+		{"build..1", true},
+		{".build", true},
+		{"build_1", true},
+		{"build+1", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.metadata, func(t *testing.T) {
+			if err := ValidateMetadata(tt.metadata); (err != nil) != tt.wantErr {
+				t.Errorf("ValidateMetadata() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestParseBumpType(t *testing.T) {
+	for _, b := range []BumpType{BumpNone, BumpPatchType, BumpMinorType, BumpMajorType} {
+		got, err := ParseBumpType(b.String())
+		if err != nil || got != b {
+			t.Errorf("ParseBumpType(%q) = %v, %v, want %v", b.String(), got, err, b)
+		}
+	}
+	if _, err := ParseBumpType("MAJOR"); err == nil {
+		t.Error("ParseBumpType(\"MAJOR\") expected error")
+	}
+}
+
+func TestCompare(t *testing.T) {
+	// Ordered by increasing precedence, see semver.org
+	ordered := []string{
+		"1.0.0-alpha",
+		"1.0.0-alpha.1",
+		"1.0.0-alpha.beta",
+		"1.0.0-beta",
+		"1.0.0-beta.2",
+		"1.0.0-beta.11",
+		"1.0.0-rc.1",
+		"1.0.0",
+		"1.0.1",
+		"1.1.0",
+		"2.0.0",
+	}
+
+	for i := range ordered {
+		for j := range ordered {
+			a, _ := Parse(ordered[i])
+			b, _ := Parse(ordered[j])
+
+			want := 0
+			if i < j {
+				want = -1
+			} else if i > j {
+				want = 1
+			}
+			if got := Compare(a, b); got != want {
+				t.Errorf("Compare(%s, %s) = %d, want %d", ordered[i], ordered[j], got, want)
+			}
+		}
+	}
+
+	a, _ := Parse("1.0.0+build.1")
+	b, _ := Parse("1.0.0+build.2")
+	if got := Compare(a, b); got != 0 {
+		t.Errorf("Compare() ignoring metadata = %d, want 0", got)
+	}
+}
+
+func TestSanitizeIdentifier(t *testing.T) {
+	tests := map[string]string{
+		"next":                "next",
+		"feature/Login_Form":  "feature-login-form",
+		"fix//double--dash":   "fix-double--dash",
+		"--weird branch name": "weird-branch-name",
+	}
+
+	for input, want := range tests {
+		if got := SanitizeIdentifier(input); got != want {
+			t.Errorf("SanitizeIdentifier(%q) = %q, want %q", input, got, want)
+		}
+	}
+}